@@ -0,0 +1,98 @@
+package lexrec
+
+import (
+	"unsafe"
+)
+
+// Arena allocates the values of emitted items, so that they can be
+// freed together rather than collected one by one.  Set a Record's
+// Arena to have Emit take each value from it.  Values given by
+// EmitValue, or changed by a Mask or Encrypt, are allocated as usual.
+type Arena interface {
+	// String returns a string holding a copy of b.
+	String(b []byte) string
+}
+
+// ByteArena is an Arena that copies values into large chunks of
+// memory, so that a record's values cost a few allocations rather than
+// one each.  Reset starts new chunks rather than reusing the old ones:
+// the bytes of a string returned by the arena are never overwritten,
+// and its chunk is freed by the garbage collector once no string
+// allocated from it is still in use.  Because the Lexer allocates
+// values as it lexes, Reset must not be called while the Lexer is
+// running, which means using a Lexer returned by NewLexerSync and
+// resetting between records or batches, e.g.:
+//
+//	arena := lexrec.NewByteArena(64 * 1024)
+//	rec.Arena = arena
+//	l, err := lexrec.NewLexerSync(name, r, rec)
+//	...
+//	for {
+//		items, err := l.NextRecord()
+//		if err == io.EOF {
+//			break
+//		}
+//		process(items)
+//		arena.Reset()
+//	}
+type ByteArena struct {
+	size   int      // size of each chunk
+	chunks [][]byte // chunks allocated so far, in order
+	n      int      // index of the chunk being filled
+}
+
+// NewByteArena returns a ByteArena that allocates memory in chunks of
+// size bytes.  A value larger than size is given a chunk of its own.
+func NewByteArena(size int) *ByteArena {
+	if size <= 0 {
+		size = 4096
+	}
+	return &ByteArena{size: size}
+}
+
+// String copies b into the arena and returns it as a string.
+func (a *ByteArena) String(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	for a.n < len(a.chunks) && cap(a.chunks[a.n])-len(a.chunks[a.n]) < len(b) {
+		a.n++
+	}
+	if a.n == len(a.chunks) {
+		size := a.size
+		if len(b) > size {
+			size = len(b)
+		}
+		a.chunks = append(a.chunks, make([]byte, 0, size))
+	}
+	c := a.chunks[a.n]
+	start := len(c)
+	c = append(c, b...)
+	a.chunks[a.n] = c
+	return unsafe.String(&c[start], len(b))
+}
+
+// Reset releases the arena's chunks, leaving the values allocated
+// from them to the garbage collector, and starts allocating from new
+// ones.
+func (a *ByteArena) Reset() {
+	a.chunks, a.n = nil, 0
+}
+
+// Len returns the number of bytes allocated since the last Reset.
+func (a *ByteArena) Len() int {
+	n := 0
+	for _, c := range a.chunks {
+		n += len(c)
+	}
+	return n
+}
+
+// value returns the bytes b as an item value, a copy allocated from
+// the Record's Arena if it has one.
+func (l *Lexer) value(b []byte) string {
+	if l.rec.Arena != nil {
+		return l.rec.Arena.String(b)
+	}
+	return string(b)
+}
@@ -0,0 +1,79 @@
+package lexrec
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestByteArena(t *testing.T) {
+	a := NewByteArena(8)
+	values := []string{"abc", "defgh", "", "0123456789", "xy"}
+	var got []string
+	for _, v := range values {
+		got = append(got, a.String([]byte(v)))
+	}
+	for i, v := range values {
+		if got[i] != v {
+			t.Errorf("expected %q, got %q", v, got[i])
+		}
+	}
+	if n := a.Len(); n != 20 {
+		t.Errorf("expected 20 bytes allocated, got %d", n)
+	}
+	if n := len(a.chunks); n != 3 {
+		t.Errorf("expected 3 chunks, got %d", n)
+	}
+	a.Reset()
+	if n := a.Len(); n != 0 {
+		t.Errorf("expected 0 bytes allocated after Reset, got %d", n)
+	}
+	if s := a.String([]byte("ijk")); s != "ijk" || len(a.chunks) != 1 {
+		t.Errorf("expected %q from a new chunk, got %q with %d chunks", "ijk", s, len(a.chunks))
+	}
+	// values allocated before Reset are not overwritten.
+	for i, v := range values {
+		if got[i] != v {
+			t.Errorf("expected %q after Reset, got %q", v, got[i])
+		}
+	}
+}
+
+func TestRecordArena(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	arena := NewByteArena(64)
+	rec.Arena = arena
+	l, err := NewLexerSync("TestRecordArena", strings.NewReader("ab 1\ncd 22\nef 333\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"ab 1", "cd 22", "ef 333"}
+	for _, e := range expect {
+		items, err := l.NextRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := []string{}
+		for _, item := range items {
+			values = append(values, item.Value)
+		}
+		if got := strings.Join(values, " "); got != e {
+			t.Errorf("expected %q, got %q", e, got)
+		}
+		if n := arena.Len(); n != len(e)-1 {
+			t.Errorf("expected %d bytes allocated, got %d", len(e)-1, n)
+		}
+		arena.Reset()
+	}
+	if _, err := l.NextRecord(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
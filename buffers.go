@@ -0,0 +1,69 @@
+package lexrec
+
+// Buffers maps item types to caller-owned buffers, into which
+// ScanRecord copies the values of a record's fields.
+type Buffers map[ItemType]*[]byte
+
+// fill copies value into the buffer for t, if there is one.  Nothing
+// is copied while StateFns are being tried.
+func (l *Lexer) fill(t ItemType, value []byte) {
+	if b, ok := l.fields[t]; ok && l.trial == 0 {
+		*b = append((*b)[:0], value...)
+	}
+}
+
+// fillString copies value into the buffer for t, if there is one.
+func (l *Lexer) fillString(t ItemType, value string) {
+	if b, ok := l.fields[t]; ok && l.trial == 0 {
+		*b = append((*b)[:0], value...)
+	}
+}
+
+// rewrites reports whether emit may change the value of an item, or
+// record it in a Sketch, so that Emit cannot copy the consumed bytes
+// into a buffer directly.
+func (l *Lexer) rewrites() bool {
+	return l.sanitizer != nil || l.encrypt != nil || l.rec.Mask != nil || l.rec.Sketches != nil
+}
+
+// ScanRecord lexes the next record of a Lexer returned by
+// NewLexerSync, copying the value of each field whose item type has a
+// buffer in bufs into that buffer, and discarding the values of the
+// others, so that once the buffers have grown to fit no garbage is
+// created per record.  The buffers of fields missing from the record
+// are left empty, and a field that occurs more than once keeps its
+// last value.  ScanRecord returns false once the input is exhausted,
+// or at once, as Scan does, for a Lexer with a goroutine of its own.
+// Otherwise Item returns the item that ended the record: an ItemEOR if
+// it was lexed successfully, or the ItemError or ItemTruncated that
+// ended it.  Values are copied as they would have been emitted, after
+// the Record's Mask and StateFns such as Encrypt and Sanitize have
+// been applied to them and its Sketches updated; values that are
+// rewritten are no longer free of garbage.  The buffers must not be
+// used by the caller while ScanRecord runs.  For example:
+//
+//	host, status := []byte{}, []byte{}
+//	bufs := lexrec.Buffers{ItemRemoteHost: &host, ItemResponseStatus: &status}
+//	for l.ScanRecord(bufs) {
+//		if l.Item().Type == lexrec.ItemEOR {
+//			...
+//		}
+//	}
+func (l *Lexer) ScanRecord(bufs Buffers) bool {
+	if l.items != nil {
+		return false
+	}
+	for _, b := range bufs {
+		*b = (*b)[:0]
+	}
+	l.fields = bufs
+	for l.Scan() {
+		switch l.item.Type {
+		case ItemEOR, ItemError, ItemTruncated:
+			l.fields = nil
+			return true
+		}
+	}
+	l.fields = nil
+	return false
+}
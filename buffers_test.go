@@ -0,0 +1,112 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanRecord(t *testing.T) {
+	rec := Record{
+		Buflen:  64,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexerSync("TestScanRecord", strings.NewReader("ab 1\ncd x\nef 23\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := []byte{}, []byte{}
+	bufs := Buffers{ItemA: &a, ItemB: &b}
+	expect := []struct {
+		t    ItemType
+		a, b string
+	}{
+		{ItemEOR, "ab", "1"},
+		{ItemError, "cd", ""},
+		{ItemEOR, "ef", "23"},
+	}
+	for _, e := range expect {
+		if !l.ScanRecord(bufs) {
+			t.Fatalf("expected a record")
+		}
+		if l.Item().Type != e.t || string(a) != e.a || string(b) != e.b {
+			t.Errorf("expected %v %q %q, got %v %q %q", e.t, e.a, e.b, l.Item().Type, a, b)
+		}
+	}
+	if l.ScanRecord(bufs) {
+		t.Errorf("expected the end of the input")
+	}
+}
+
+func TestScanRecordAllocs(t *testing.T) {
+	rec := Record{
+		Buflen:  4096,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexerSync("TestScanRecordAllocs", strings.NewReader(strings.Repeat("abc 123\n", 10000)), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := []byte{}, []byte{}
+	bufs := Buffers{ItemA: &a, ItemB: &b}
+	for i := 0; i < 100; i++ {
+		l.ScanRecord(bufs)
+	}
+	if n := testing.AllocsPerRun(1000, func() { l.ScanRecord(bufs) }); n > 0 {
+		t.Errorf("expected no allocations per record, got %v", n)
+	}
+}
+
+func TestScanRecordDirectEmit(t *testing.T) {
+	rec := Record{
+		Buflen:  32,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemIgnore, LatLonSplit(",", ItemA, ItemB), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexerSync("TestScanRecordDirectEmit", strings.NewReader("37.7749,-122.4194\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, lon := []byte{}, []byte{}
+	if !l.ScanRecord(Buffers{ItemA: &lat, ItemB: &lon}) {
+		t.Fatalf("expected a record")
+	}
+	if l.Item().Type != ItemEOR || string(lat) != "37.7749" || string(lon) != "-122.4194" {
+		t.Errorf("expected ItemEOR \"37.7749\" \"-122.4194\", got %v %q %q", l.Item().Type, lat, lon)
+	}
+}
+
+func TestScanRecordMask(t *testing.T) {
+	rec := Record{
+		Buflen:  64,
+		ErrorFn: SkipPast("\n"),
+		Mask:    MaskPAN,
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexerSync("TestScanRecordMask", strings.NewReader("pan 5500000000000004\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := []byte{}, []byte{}
+	if !l.ScanRecord(Buffers{ItemA: &a, ItemB: &b}) {
+		t.Fatalf("expected a record")
+	}
+	if string(a) != "pan" || string(b) != "************0004" {
+		t.Errorf("expected \"pan\" \"************0004\", got %q %q", a, b)
+	}
+}
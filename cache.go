@@ -0,0 +1,89 @@
+package lexrec
+
+// Cache remembers the items lexed from recently seen records, keyed by
+// their raw bytes, so that a record repeated verbatim, e.g., a health
+// check or heartbeat line, is lexed once and its items re-emitted, at
+// their new positions, without running the StateFns again.  A raw
+// record runs up to and including the Record's Terminator or, if it
+// has none, a newline.  Only records that lex without error, and whose
+// bindings consume exactly the raw record, are cached.  Since a hit
+// runs no StateFns, it updates neither a Coverage nor any Sketches.
+// Set a Record's Cache to a *Cache to apply it.  The counts are
+// updated by the Lexer's goroutine, and are only safe to read once
+// ItemEOF has been received.
+type Cache struct {
+	Size    int   // maximum number of records held; if <= 0, 1024
+	Hits    int64 // records re-emitted from the cache
+	Misses  int64 // records lexed
+	entries map[string][]Item
+}
+
+// lookup returns the items cached for the raw record, or false.
+func (c *Cache) lookup(raw []byte) ([]Item, bool) {
+	items, ok := c.entries[string(raw)]
+	return items, ok
+}
+
+// store caches items for the raw record key.  Once the cache is full,
+// it is emptied and starts again.
+func (c *Cache) store(key string, items []Item) {
+	size := c.Size
+	if size <= 0 {
+		size = 1024
+	}
+	if c.entries == nil || len(c.entries) >= size {
+		c.entries = make(map[string][]Item, size)
+	}
+	c.entries[key] = items
+}
+
+// cached lexes the record at start, the current position, using the
+// Record's Cache: on a hit its items are re-emitted and the raw record
+// skipped, otherwise it is lexed as usual and, if clean, cached.  It
+// reports whether the record failed.
+func (l *Lexer) cached(start int64) bool {
+	c := l.rec.Cache
+	pos, rpos, width, eof := l.pos, l.rpos, l.width, l.eof
+	l.toTerminator()
+	term := l.rec.Terminator
+	if term == nil {
+		term = newline
+	}
+	term(l)
+	raw := l.buf[l.start:l.pos]
+	if items, ok := c.lookup(raw); ok && len(raw) > 0 {
+		c.Hits++
+		for _, item := range items {
+			item.Pos += start
+			l.send(item)
+		}
+		l.Skip()
+		return false
+	}
+	key := string(raw)
+	l.pos, l.rpos, l.width, l.eof = pos, rpos, width, eof
+	c.Misses++
+
+	b := &batch{start: start}
+	l.batch = b
+	failed := l.bindings()
+	l.batch = nil
+	if !failed && len(key) > 0 && l.tokenPos()-start == int64(len(key)) {
+		c.store(key, b.items)
+	}
+	return failed
+}
+
+// batch holds the items sent while a record is lexed, with positions
+// relative to the start of the record.
+type batch struct {
+	start int64
+	items []Item
+}
+
+// add appends item to the batch.
+func (b *batch) add(item Item) {
+	item.Pos -= b.start
+	item = item.Clone()
+	b.items = append(b.items, item)
+}
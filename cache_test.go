@@ -0,0 +1,54 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	input := "ping 1\nping 1\npong 2\nping 1\nbad x\nbad x\nping 1"
+	want := lexAll(t, "TestCache", input, rec)
+
+	c := &Cache{Size: 8}
+	rec.Cache = c
+	got := lexAll(t, "TestCache", input, rec)
+	if summarize(got) != summarize(want) {
+		t.Fatalf("expected %s, got %s", summarize(want), summarize(got))
+	}
+	for i := range got {
+		if got[i].Pos != want[i].Pos {
+			t.Errorf("expected %v at %d, got %d", got[i], want[i].Pos, got[i].Pos)
+		}
+	}
+	// the last record, with no newline, differs from the others.
+	if c.Hits != 2 || c.Misses != 5 {
+		t.Errorf("expected 2 hits and 5 misses, got %d and %d", c.Hits, c.Misses)
+	}
+}
+
+func TestCacheSize(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		Cache:   &Cache{Size: 2},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	lexAll(t, "TestCacheSize", strings.Repeat("a\nb\nc\n", 2)+"c\n", rec)
+	if c := rec.Cache; c.Hits != 1 || c.Misses != 6 {
+		t.Errorf("expected 1 hit and 6 misses, got %d and %d", c.Hits, c.Misses)
+	}
+	if _, err := Compile(rec); err == nil {
+		t.Errorf("expected Compile to reject a Cache")
+	}
+}
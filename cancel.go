@@ -0,0 +1,44 @@
+package lexrec
+
+import (
+	"fmt"
+)
+
+// done returns a channel that is closed when the Lexer's context is
+// canceled, or nil if it has no context.
+func (l *Lexer) done() <-chan struct{} {
+	if l.ctx == nil {
+		return nil
+	}
+	return l.ctx.Done()
+}
+
+// canceled reports whether the Lexer's context has been canceled.
+func (l *Lexer) canceled() bool {
+	return l.ctx != nil && l.ctx.Err() != nil
+}
+
+// canceledItem returns the ItemError reported by NextItem once a
+// canceled Lexer has stopped.
+func (l *Lexer) canceledItem() Item {
+	err := l.ctx.Err()
+	return Item{Type: ItemError, Pos: l.lastPos, Value: fmt.Sprintf("%s: %v", l.name, err), Err: Error{State: -1, Cause: err}}
+}
+
+// Close stops the Lexer, abandoning any input that has not yet been
+// lexed, and waits for its goroutine to finish.  Close returns the
+// first error, other than io.EOF, encountered reading the input.  Once
+// closed, NextItem returns an ItemError reporting the cancellation.
+// Calling Close more than once has no further effect.
+func (l *Lexer) Close() error {
+	if l.stop != nil {
+		l.stop()
+	}
+	if l.items == nil {
+		l.ended, l.queue, l.head = true, nil, 0
+		return l.err
+	}
+	for range l.items {
+	}
+	return l.err
+}
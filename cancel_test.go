@@ -0,0 +1,96 @@
+package lexrec
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewLexerContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := strings.NewReader(strings.Repeat("a\n", 100000))
+	l, err := NewLexerContext(ctx, "TestNewLexerContext", r, aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.NextItem()
+	cancel()
+	for {
+		item := l.NextItem()
+		if item.Type == ItemEOF {
+			t.Fatalf("expected cancellation, got %v", item)
+		}
+		if item.Type == ItemError {
+			if !strings.HasSuffix(item.Value, "context canceled") {
+				t.Errorf("expected a context canceled error, got %q", item.Value)
+			}
+			break
+		}
+	}
+	if !finished(l) {
+		t.Errorf("expected items channel to be closed after cancellation")
+	}
+}
+
+func TestNewLexerContextPaused(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := strings.NewReader(strings.Repeat("a\n", 100000))
+	l, err := NewLexerContext(ctx, "TestNewLexerContextPaused", r, aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Pause()
+	cancel()
+	l.Drain()
+	if item := l.NextItem(); item.Type != ItemError {
+		t.Errorf("expected an ItemError, got %v", item)
+	}
+}
+
+// errReader returns the bytes of s followed by err.
+type errReader struct {
+	s   string
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if r.s == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.s)
+	r.s = r.s[n:]
+	return n, nil
+}
+
+func TestLexerClose(t *testing.T) {
+	r := strings.NewReader(strings.Repeat("a\n", 100000))
+	l, err := NewLexer("TestLexerClose", r, aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.NextItem()
+	if err := l.Close(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !finished(l) {
+		t.Errorf("expected items channel to be closed after Close")
+	}
+	if item := l.NextItem(); item.Type != ItemError {
+		t.Errorf("expected an ItemError after Close, got %v", item)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("expected no error from a second Close, got %v", err)
+	}
+
+	failure := errors.New("read failed")
+	l, err = NewLexer("TestLexerClose", &errReader{"aaa", failure}, aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for l.NextItem().Type != ItemError {
+	}
+	if err := l.Close(); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
@@ -0,0 +1,60 @@
+package lexrec
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Canonicalize lexes r using rec and writes each successfully lexed
+// record to w in a normalized form: each item value is trimmed of
+// surrounding whitespace and, if it is a double-quoted string, is
+// unquoted; values are then joined by tabs and the record is
+// terminated by a newline.  Tabs, newlines and backslashes within
+// values are escaped as \t, \n and \\ so that each record occupies
+// exactly one line.  Canonicalize returns the ItemError, or
+// ItemTruncated, of each record that failed to lex, and the first
+// error returned by w.
+func Canonicalize(r io.Reader, rec Record, w io.Writer) (failures []Item, err error) {
+	l, err := NewLexer("Canonicalize", r, rec)
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(w)
+	for {
+		items, failed, eof := readRecord(l)
+		if failed {
+			failures = append(failures, items[len(items)-1])
+		} else if len(items) > 0 && err == nil {
+			for i, item := range items {
+				if i > 0 {
+					bw.WriteByte('\t')
+				}
+				bw.WriteString(canonicalValue(item.Value))
+			}
+			err = bw.WriteByte('\n')
+		}
+		if eof {
+			if err == nil {
+				err = bw.Flush()
+			}
+			return
+		}
+	}
+}
+
+// canonicalEscaper escapes the characters that would break the
+// one-record-per-line form written by Canonicalize.
+var canonicalEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`)
+
+// canonicalValue returns the normalized form of an item value.
+func canonicalValue(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		if s, err := strconv.Unquote(v); err == nil {
+			v = s
+		}
+	}
+	return canonicalEscaper.Replace(v)
+}
@@ -0,0 +1,30 @@
+package lexrec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Spaces, false},
+			{ItemB, Quote, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	buf := new(bytes.Buffer)
+	failures, err := Canonicalize(strings.NewReader("ab   \"x\\ty\"\n1 \"z\"\nc \" q \"\n"), rec, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 || failures[0].Type != ItemError {
+		t.Errorf("expected one failure, got %v", failures)
+	}
+	if expect := "ab\tx\\ty\nc\t q \n"; buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+}
@@ -0,0 +1,100 @@
+package lexrec
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// InvariantError is returned by CheckStateFn when a StateFn breaks one
+// of the rules the Lexer relies on.
+type InvariantError struct {
+	StateFn string // name of the StateFn
+	Input   string // input on which the rule was broken
+	Rule    string // description of the rule that was broken
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("%s: %s, on input %q", e.StateFn, e.Rule, e.Input)
+}
+
+// checker records the first rule broken by a StateFn while it runs
+// under CheckStateFn.
+type checker struct {
+	backups int    // number of calls to Backup since the last Next
+	broken  string // the first rule broken, or "" if none
+}
+
+// next notes a call to Next.
+func (c *checker) next() {
+	c.backups = 0
+}
+
+// backup notes a call to Backup.
+func (c *checker) backup() {
+	if c.backups++; c.backups > 1 && c.broken == "" {
+		c.broken = "Backup called more than once after Next"
+	}
+}
+
+// CheckStateFn runs fn against n inputs produced by gen, using seed as
+// the source of randomness, and checks that it keeps to the rules the
+// Lexer relies on of every StateFn:
+//
+//   - the current position is never left before the start of the
+//     token;
+//   - Backup is called at most once after each call to Next;
+//   - on success the token is either emitted or skipped;
+//   - on success at least one byte is consumed, so that a StateFn
+//     repeated until it fails always ends.
+//
+// Each input is checked whole, followed by a newline, and cut short at
+// every byte, both as a single read and a byte at a time.  A StateFn
+// that breaks these rules can corrupt the item stream in confusing
+// ways.  CheckStateFn returns an *InvariantError describing the first
+// broken rule, or nil.
+func CheckStateFn(fn StateFn, gen GenFn, n int, seed int64) error {
+	r := rand.New(rand.NewSource(seed))
+	var b []byte
+	for i := 0; i < n; i++ {
+		b = gen(r, b[:0])
+		inputs := []string{string(b), string(b) + "\n"}
+		for j := 0; j < len(b); j++ {
+			inputs = append(inputs, string(b[:j]))
+		}
+		for _, input := range inputs {
+			for _, sizes := range [][]int{nil, {1}} {
+				if rule := checkInput(fn, input, sizes); rule != "" {
+					return &InvariantError{StateFn: funcName(fn), Input: input, Rule: rule}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkInput runs fn once at the start of input, read in chunks of the
+// given sizes, and returns the first rule it breaks, or "".
+func checkInput(fn StateFn, input string, sizes []int) string {
+	c := &checker{}
+	l := &Lexer{
+		name: "CheckStateFn",
+		r:    strings.NewReader(input),
+		rec:  Record{Buflen: 16, ReadSizes: sizes},
+		next: make([]byte, 16),
+	}
+	l.out = func(item Item) {}
+	l.checker = c
+	ok := fn(l, ItemEOF+1, true)
+	switch {
+	case c.broken != "":
+		return c.broken
+	case l.pos < l.start:
+		return "position left before the start of the token"
+	case ok && l.start != l.pos:
+		return "succeeded without emitting or skipping the token"
+	case ok && l.rpos == 0:
+		return "succeeded without consuming any input"
+	}
+	return ""
+}
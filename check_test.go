@@ -0,0 +1,66 @@
+package lexrec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckStateFn(t *testing.T) {
+	tests := []struct {
+		fn  StateFn
+		gen GenFn
+	}{
+		{Digits, GenDigits(1, 6)},
+		{Letters, GenLetters(1, 6)},
+		{Literal("GET"), GenLiteral("GET")},
+		{OneOf(Digits, Accept("-", true)), GenChoice("-", "200", "404")},
+		{Quote, GenChoice(`"a b"`, `"a \" b"`, `"é"`)},
+		{Sequence(Accept("[", true), Digits, Accept("]", true)), GenChoice("[1]", "[12]")},
+	}
+	for _, test := range tests {
+		if err := CheckStateFn(test.fn, test.gen, 20, 1); err != nil {
+			t.Errorf("unexpected violation: %v", err)
+		}
+	}
+}
+
+func TestCheckStateFnViolations(t *testing.T) {
+	tests := []struct {
+		fn   StateFn
+		rule string
+	}{
+		{func(l *Lexer, t ItemType, emit bool) bool {
+			l.Next()
+			l.Next()
+			l.Backup()
+			l.Backup()
+			return false
+		}, "Backup called more than once"},
+		{func(l *Lexer, t ItemType, emit bool) bool {
+			l.Next()
+			l.Skip()
+			l.pos--
+			return false
+		}, "before the start of the token"},
+		{func(l *Lexer, t ItemType, emit bool) bool {
+			return l.AcceptRun("ab")
+		}, "without emitting or skipping"},
+		{func(l *Lexer, t ItemType, emit bool) bool {
+			l.AcceptRun("ab")
+			l.Emit(t)
+			return true
+		}, "without consuming any input"},
+	}
+	for i, test := range tests {
+		err := CheckStateFn(test.fn, GenChoice("ab", "ba"), 5, 1)
+		var ie *InvariantError
+		if !errors.As(err, &ie) {
+			t.Errorf("%d: expected an *InvariantError, got %v", i, err)
+			continue
+		}
+		if !strings.Contains(ie.Rule, test.rule) {
+			t.Errorf("%d: expected a violation of %q, got %v", i, test.rule, err)
+		}
+	}
+}
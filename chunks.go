@@ -0,0 +1,85 @@
+package lexrec
+
+import (
+	"io"
+)
+
+// ChunkReader is an io.Reader that limits the size of each Read from
+// an underlying reader, taking the limits from a list of sizes in turn
+// and repeating them once the list is exhausted.  It makes the read
+// boundaries a Lexer sees deterministic, e.g., a byte at a time, or at
+// prime intervals, so that bugs that only appear when a token is
+// split across reads can be reproduced.
+type ChunkReader struct {
+	r     io.Reader
+	sizes []int
+	n     int
+}
+
+// NewChunkReader returns a ChunkReader reading from r in chunks of at
+// most the given sizes.  A size < 1, or an empty list, leaves a Read
+// unlimited.
+func NewChunkReader(r io.Reader, sizes ...int) *ChunkReader {
+	return &ChunkReader{r: r, sizes: sizes}
+}
+
+// Read reads up to the next of the sizes, or len(p) if it is smaller,
+// bytes from the underlying reader into p.
+func (c *ChunkReader) Read(p []byte) (int, error) {
+	if len(c.sizes) > 0 {
+		p = limit(p, c.sizes[c.n%len(c.sizes)])
+		c.n++
+	}
+	return c.r.Read(p)
+}
+
+// SplitReader is an io.Reader that ends its reads from an underlying
+// reader at given offsets, so that the input can be split at exact
+// token boundaries, or a byte either side of them.
+type SplitReader struct {
+	r   io.Reader
+	at  []int64
+	pos int64
+}
+
+// NewSplitReader returns a SplitReader reading from r that ends a Read
+// at each of the offsets in at, which must be in increasing order.
+// Reads after the last offset are unlimited.
+func NewSplitReader(r io.Reader, at ...int64) *SplitReader {
+	return &SplitReader{r: r, at: at}
+}
+
+// Read reads up to len(p) bytes from the underlying reader into p,
+// stopping at the next split offset.
+func (s *SplitReader) Read(p []byte) (int, error) {
+	for len(s.at) > 0 && s.at[0] <= s.pos {
+		s.at = s.at[1:]
+	}
+	if len(s.at) > 0 {
+		p = limit(p, int(s.at[0]-s.pos))
+	}
+	n, err := s.r.Read(p)
+	s.pos += int64(n)
+	return n, err
+}
+
+// limit returns p truncated to at most size bytes, if size is > 0.
+func limit(p []byte, size int) []byte {
+	if size > 0 && len(p) > size {
+		return p[:size]
+	}
+	return p
+}
+
+// chunk returns the buffer the Lexer should read into next: all of
+// l.next, or as much of it as the next of the Record's ReadSizes
+// allows.
+func (l *Lexer) chunk() []byte {
+	sizes := l.rec.ReadSizes
+	if len(sizes) == 0 {
+		return l.next
+	}
+	size := sizes[l.reads%len(sizes)]
+	l.reads++
+	return limit(l.next, size)
+}
@@ -0,0 +1,92 @@
+package lexrec
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+var chunkRecord = Record{
+	Buflen:  64,
+	ErrorFn: SkipPast("\n"),
+	States: []Binding{
+		{ItemA, Literal("héllo"), true},
+		{ItemIgnore, Accept(" ", true), false},
+		{ItemB, ExceptRun("\n", true), true},
+		{ItemIgnore, Accept("\n", true), false},
+	}}
+
+const chunkInput = "héllo wörld\nhéllo ✓ two\nhullo bad\nhéllo end\n"
+
+func TestReadSizes(t *testing.T) {
+	want := summarize(lexAll(t, "TestReadSizes", chunkInput, chunkRecord))
+	for _, sizes := range [][]int{{1}, {2}, {2, 3, 5, 7}, {13}, {0, 1}} {
+		rec := chunkRecord
+		rec.ReadSizes = sizes
+		if got := summarize(lexAll(t, "TestReadSizes", chunkInput, rec)); got != want {
+			t.Errorf("%v: expected %s, got %s", sizes, want, got)
+		}
+	}
+}
+
+func TestSplitReaderBoundaries(t *testing.T) {
+	want := summarize(lexAll(t, "TestSplitReaderBoundaries", chunkInput, chunkRecord))
+	for at := int64(1); at < int64(len(chunkInput)); at++ {
+		l, err := NewLexerSync("TestSplitReaderBoundaries", NewSplitReader(strings.NewReader(chunkInput), at-1, at, at+1), chunkRecord)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var items []Item
+		for l.Scan() {
+			items = append(items, l.Item())
+		}
+		if got := summarize(items); got != want {
+			t.Errorf("split at %d: expected %s, got %s", at, want, got)
+		}
+	}
+}
+
+// reads returns the sizes of the reads r returns into a buffer of n
+// bytes.
+func reads(t *testing.T, r io.Reader, n int) []int {
+	var sizes []int
+	p := make([]byte, n)
+	for {
+		k, err := r.Read(p)
+		if k > 0 {
+			sizes = append(sizes, k)
+		}
+		if err == io.EOF {
+			return sizes
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestChunkReader(t *testing.T) {
+	got := reads(t, NewChunkReader(strings.NewReader(strings.Repeat("x", 20)), 3, 5), 4)
+	want := []int{3, 4, 3, 4, 3, 3}
+	if len(got) != len(want) {
+		t.Fatalf("expected reads of %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected reads of %v, got %v", want, got)
+		}
+	}
+}
+
+func TestSplitReader(t *testing.T) {
+	got := reads(t, NewSplitReader(strings.NewReader(strings.Repeat("x", 20)), 2, 3, 9), 100)
+	want := []int{2, 1, 6, 11}
+	if len(got) != len(want) {
+		t.Fatalf("expected reads of %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected reads of %v, got %v", want, got)
+		}
+	}
+}
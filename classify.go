@@ -0,0 +1,56 @@
+package lexrec
+
+import (
+	"unicode"
+)
+
+// Classifier defines the rune classes used by the Digits, Letters,
+// Spaces and Number StateFns.  A Record may supply its own Classifier
+// to narrow or widen what those StateFns accept, e.g., to reject
+// full-width digits that unicode.IsDigit would otherwise accept.
+type Classifier struct {
+	IsDigit  func(r rune) bool // reports whether r is a digit
+	IsLetter func(r rune) bool // reports whether r is a letter
+	IsSpace  func(r rune) bool // reports whether r is whitespace
+}
+
+// UnicodeClassifier classifies runes using the Unicode categories
+// from the unicode package.  It is used when a Record does not
+// specify a Classifier.
+var UnicodeClassifier = &Classifier{
+	IsDigit:  unicode.IsDigit,
+	IsLetter: unicode.IsLetter,
+	IsSpace:  unicode.IsSpace,
+}
+
+// ASCIIClassifier classifies runes using ASCII-only classes: [0-9],
+// [A-Za-z], and the ASCII whitespace characters.
+var ASCIIClassifier = &Classifier{
+	IsDigit:  isASCIIDigit,
+	IsLetter: isASCIILetter,
+	IsSpace:  isASCIISpace,
+}
+
+func isASCIIDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+func isASCIILetter(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+func isASCIISpace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
+
+// Classifier returns the Classifier in effect for the Lexer's Record.
+func (l *Lexer) Classifier() *Classifier {
+	if l.rec.Classifier != nil {
+		return l.rec.Classifier
+	}
+	return UnicodeClassifier
+}
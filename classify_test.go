@@ -0,0 +1,26 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestClassifierASCIIDigits(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemEmit, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+
+	items := lexAll(t, "TestClassifierASCIIDigits", "１２\n", rec)
+	if items[0].Type != ItemEmit || items[0].Value != "１２" {
+		t.Errorf("expected full-width digits with UnicodeClassifier, got %q", items[0])
+	}
+
+	rec.Classifier = ASCIIClassifier
+	items = lexAll(t, "TestClassifierASCIIDigits", "１２\n", rec)
+	if items[0].Type != ItemError {
+		t.Errorf("expected ItemError with ASCIIClassifier, got %q", items[0])
+	}
+}
@@ -0,0 +1,212 @@
+// Command lexserve is an HTTP service that lexes uploaded or streamed
+// input with a named Record and streams back the parsed records, so
+// that the lexer can be used from outside of Go.
+//
+// Usage:
+//
+//	lexserve [-addr :8080] [-formats formats.conf]
+//
+// Formats are looked up by name in a lexrec.Registry, which holds the
+// built-in "ncsa" format and any read from the formats file.  Each
+// line of the file, other than blank lines and those beginning with
+// '#', names a format and gives a regular expression matched against
+// each newline-terminated record, whose named groups are the fields:
+//
+//	kv	(?P<key>\w+)=(?P<value>.*)
+//
+// The formats file is read again on SIGHUP.  The endpoints are:
+//
+//	GET  /formats          the names of the formats, one per line
+//	POST /lex/{name}       the records of the request body, as JSONL
+//	POST /lex/{name}?format=binary
+//	                       the items of the request body, in the
+//	                       binary format read by lexrec.ItemDecoder
+//
+// Each JSONL line holds either the fields of a record, by name, or the
+// error for a record that failed to lex.  A failure to read the body
+// ends the response with its error:
+//
+//	{"record":1,"fields":{"key":"a","value":"1"}}
+//	{"record":2,"error":"record does not match ...","pos":4}
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"github.com/jimrobinson/lexrec"
+)
+
+// ncsa matches the NCSA Common Log Format.
+var ncsa = regexp.MustCompile(`(?P<host>\S+) (?P<logname>\S+) (?P<user>\S+) \[(?P<time>[^\]]+)\] "(?P<method>\S+) (?P<path>\S+) (?P<protocol>[^"]+)" (?P<status>\d{3}|-) (?P<bytes>\d+|-)`)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	formats := flag.String("formats", "", "file of named regular expression formats")
+	flag.Parse()
+
+	reg := lexrec.NewRegistry()
+	if err := reg.Register("ncsa", regexpRecord(ncsa)); err != nil {
+		log.Fatal(err)
+	}
+	if *formats != "" {
+		if err := reload(reg, *formats); err != nil {
+			log.Fatal(err)
+		}
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := reload(reg, *formats); err != nil {
+					log.Print(err)
+				}
+			}
+		}()
+	}
+	log.Fatal(http.ListenAndServe(*addr, newHandler(reg)))
+}
+
+// regexpRecord returns a Record of newline-terminated records matched
+// by re, with an item type for each of its named groups following the
+// type of the record itself.
+func regexpRecord(re *regexp.Regexp) lexrec.Record {
+	const itemRecord = lexrec.ItemEOF + 1
+	types := lexrec.NameMap{}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			types[itemRecord+lexrec.ItemType(i)] = name
+		}
+	}
+	return lexrec.RegexpRecord(re, itemRecord, types)
+}
+
+// reload replaces the formats of reg read from path.
+func reload(reg *lexrec.Registry, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	recs, err := readFormats(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return reg.Reload(path, recs)
+}
+
+// readFormats reads the named regular expression formats of r.
+func readFormats(r io.Reader) (map[string]lexrec.Record, error) {
+	recs := map[string]lexrec.Record{}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		name, expr, ok := strings.Cut(line, "\t")
+		if !ok {
+			name, expr, ok = strings.Cut(line, " ")
+		}
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a name and a regular expression", n)
+		}
+		re, err := regexp.Compile(strings.TrimSpace(expr))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		recs[name] = regexpRecord(re)
+	}
+	return recs, s.Err()
+}
+
+// newHandler returns the handler for the endpoints of the service.
+func newHandler(reg *lexrec.Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /formats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, name := range reg.Names() {
+			fmt.Fprintln(w, name)
+		}
+	})
+	mux.HandleFunc("POST /lex/{name}", func(w http.ResponseWriter, r *http.Request) {
+		c, ok := reg.Lookup(r.PathValue("name"))
+		if !ok {
+			http.Error(w, "unknown format", http.StatusNotFound)
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "jsonl" && format != "binary" {
+			http.Error(w, "format must be jsonl or binary", http.StatusBadRequest)
+			return
+		}
+		// records are streamed back while the body is still being
+		// read, which HTTP/1.x servers do not allow by default.
+		http.NewResponseController(w).EnableFullDuplex()
+		l := c.NewLexerContext(r.Context(), r.PathValue("name"), r.Body)
+		defer l.Close()
+		names := c.Record().Names
+		if format == "binary" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			if err := lexrec.EncodeItems(l, w, names); err != nil {
+				log.Print(err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/jsonl")
+		writeJSONL(w, l, names)
+	})
+	return mux
+}
+
+// record is a JSONL line of the response.
+type record struct {
+	Record int64             `json:"record"`
+	Fields map[string]string `json:"fields,omitempty"`
+	Error  string            `json:"error,omitempty"`
+	Pos    *int64            `json:"pos,omitempty"`
+}
+
+// writeJSONL writes the records of l to w as JSONL, flushing each
+// one to the client as it is written.
+func writeJSONL(w http.ResponseWriter, l *lexrec.Lexer, names lexrec.NameMap) {
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	for n := int64(1); ; n++ {
+		items, err := l.NextRecord()
+		if err == io.EOF {
+			return
+		}
+		rec := record{Record: n}
+		if err != nil {
+			pos := l.LastPos()
+			rec.Error, rec.Pos = err.Error(), &pos
+		} else {
+			rec.Fields = map[string]string{}
+			for _, item := range items {
+				if name, ok := names[item.Type]; ok {
+					rec.Fields[name] = item.Value
+				}
+			}
+		}
+		// a read error or cancellation ends the input for good.
+		if enc.Encode(rec) != nil || errors.As(err, new(*lexrec.ReadError)) ||
+			errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jimrobinson/lexrec"
+)
+
+func TestLexserve(t *testing.T) {
+	recs, err := readFormats(strings.NewReader("# key=value pairs\nkv\t(?P<key>\\w+)=(?P<value>.*)\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := lexrec.NewRegistry()
+	if err := reg.Reload("formats.conf", recs); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newHandler(reg))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/formats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "kv\n" {
+		t.Errorf("expected formats %q, got %q", "kv\n", body)
+	}
+
+	resp, err = http.Post(srv.URL+"/lex/kv", "text/plain", strings.NewReader("a=1\nbad\nb=x y\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	expect := []string{
+		`{"record":1,"fields":{"key":"a","value":"1"}}`,
+		`{"record":2,"error":`,
+		`{"record":3,"fields":{"key":"b","value":"x y"}}`,
+	}
+	if len(lines) != len(expect) {
+		t.Fatalf("expected %d lines, got %q", len(expect), lines)
+	}
+	for i := range expect {
+		if !strings.HasPrefix(lines[i], expect[i]) {
+			t.Errorf("expected %s, got %s", expect[i], lines[i])
+		}
+	}
+
+	resp, err = http.Post(srv.URL+"/lex/kv?format=binary", "text/plain", strings.NewReader("a=1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	d, err := lexrec.NewItemDecoder(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for {
+		item, err := d.Decode()
+		if err != nil {
+			break
+		}
+		values = append(values, item.Value)
+	}
+	if strings.Join(values, ",") != "a,1,," {
+		t.Errorf("expected items a, 1, EOR and EOF, got %q", values)
+	}
+
+	resp, err = http.Post(srv.URL+"/lex/missing", "text/plain", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown format, got %d", resp.StatusCode)
+	}
+
+	// a bad format is refused before any of the body is read.
+	counter := &readCounter{r: strings.NewReader("a=1\n")}
+	req := httptest.NewRequest("POST", "/lex/kv?format=xml", counter)
+	rw := httptest.NewRecorder()
+	newHandler(reg).ServeHTTP(rw, req)
+	if rw.Code != http.StatusBadRequest || counter.n != 0 {
+		t.Errorf("expected status 400 without reading the body, got %d after %d reads", rw.Code, counter.n)
+	}
+}
+
+// readCounter counts the calls made to Read.
+type readCounter struct {
+	r io.Reader
+	n int
+}
+
+func (c *readCounter) Read(p []byte) (int, error) {
+	c.n++
+	return c.r.Read(p)
+}
+
+func TestRegexpRecord(t *testing.T) {
+	rec := regexpRecord(regexp.MustCompile(`(?P<key>\w+)=(?P<value>.*)`))
+	if name, ok := rec.Names[rec.States[0].ItemType]; ok {
+		t.Errorf("expected the record's item type to be unnamed, got %q", name)
+	}
+	if len(rec.Names) != 2 {
+		t.Errorf("expected 2 named groups, got %v", rec.Names)
+	}
+}
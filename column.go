@@ -0,0 +1,153 @@
+package lexrec
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ColumnKind identifies the Go type used to hold the values of a
+// Column.
+type ColumnKind int
+
+const (
+	StringColumn ColumnKind = iota // values are held in Column.Strings
+	IntColumn                      // values are parsed as base 10 integers into Column.Ints
+	FloatColumn                    // values are parsed as floating point numbers into Column.Floats
+)
+
+// ColumnSpec selects a field to be gathered into a Column.
+type ColumnSpec struct {
+	Type ItemType   // item type of the field
+	Kind ColumnKind // how the field's values are stored
+}
+
+// Column holds the values of one field for a batch of records.  Only
+// the slice matching Kind is populated.  Valid[i] is false if record i
+// had no value for the field or if the value could not be converted
+// to Kind, in which case the corresponding value is the zero value.
+type Column struct {
+	ColumnSpec
+	Strings []string
+	Ints    []int64
+	Floats  []float64
+	Valid   []bool
+}
+
+// Batch holds the columns gathered from a run of records.
+type Batch struct {
+	Len     int       // number of records in the batch
+	Columns []*Column // one column per ColumnSpec, in the order given
+}
+
+// reset empties the batch for reuse.
+func (b *Batch) reset() {
+	b.Len = 0
+	for _, c := range b.Columns {
+		c.Strings = c.Strings[:0]
+		c.Ints = c.Ints[:0]
+		c.Floats = c.Floats[:0]
+		c.Valid = c.Valid[:0]
+	}
+}
+
+// append adds the value of the column for the next record.
+func (c *Column) append(value string, ok bool) {
+	switch c.Kind {
+	case StringColumn:
+		c.Strings = append(c.Strings, value)
+	case IntColumn:
+		n, err := strconv.ParseInt(value, 10, 64)
+		ok = ok && err == nil
+		c.Ints = append(c.Ints, n)
+	case FloatColumn:
+		f, err := strconv.ParseFloat(value, 64)
+		ok = ok && err == nil
+		c.Floats = append(c.Floats, f)
+	}
+	c.Valid = append(c.Valid, ok)
+}
+
+// ReadBatches reads records from l until ItemEOF, gathering the fields
+// selected by specs into column vectors and calling fn with each batch
+// of size records, and with any final partial batch.  The Batch and
+// its slices are reused, fn must copy any values it retains.  Records
+// that fail to lex are skipped.  ReadBatches returns the first error
+// returned by fn.
+func ReadBatches(l *Lexer, specs []ColumnSpec, size int, fn func(b *Batch) error) error {
+	if size < 1 {
+		return fmt.Errorf("batch size must be > 0: %d", size)
+	}
+	b := &Batch{Columns: make([]*Column, len(specs))}
+	index := make(map[ItemType]int, len(specs))
+	for i, spec := range specs {
+		b.Columns[i] = &Column{ColumnSpec: spec}
+		index[spec.Type] = i
+	}
+	values := make([]string, len(specs))
+	found := make([]bool, len(specs))
+	for {
+		items, failed, eof := readRecord(l)
+		if !failed && len(items) > 0 {
+			for i := range found {
+				values[i], found[i] = "", false
+			}
+			for _, item := range items {
+				if i, ok := index[item.Type]; ok {
+					values[i], found[i] = item.Value, true
+				}
+			}
+			for i, c := range b.Columns {
+				c.append(values[i], found[i])
+			}
+			b.Len++
+			if b.Len == size {
+				if err := fn(b); err != nil {
+					return err
+				}
+				b.reset()
+			}
+		}
+		if eof {
+			if b.Len > 0 {
+				return fn(b)
+			}
+			return nil
+		}
+	}
+}
+
+// ColumnSink receives the values gathered by WriteColumns, allowing
+// columnar writers such as Parquet or ORC encoders to be plugged in
+// without lexrec depending on them.  Columns are identified by their
+// index in the ColumnSpec slice given to WriteColumns.
+type ColumnSink interface {
+	AppendString(col int, v string) // append a value to a StringColumn
+	AppendInt(col int, v int64)     // append a value to an IntColumn
+	AppendFloat(col int, v float64) // append a value to a FloatColumn
+	AppendNull(col int)             // append a missing or unconvertible value
+	Flush(rows int) error           // end a batch of rows
+}
+
+// WriteColumns reads batches of size records from l, as ReadBatches
+// does, appending each column's values to sink in record order and
+// calling sink.Flush at the end of each batch.  It returns the first
+// error returned by sink.Flush.
+func WriteColumns(l *Lexer, specs []ColumnSpec, size int, sink ColumnSink) error {
+	return ReadBatches(l, specs, size, func(b *Batch) error {
+		for i, c := range b.Columns {
+			for row := 0; row < b.Len; row++ {
+				switch {
+				case !c.Valid[row]:
+					sink.AppendNull(i)
+				case c.Kind == IntColumn:
+					sink.AppendInt(i, c.Ints[row])
+				case c.Kind == FloatColumn:
+					sink.AppendFloat(i, c.Floats[row])
+				default:
+					sink.AppendString(i, c.Strings[row])
+				}
+			}
+		}
+		return sink.Flush(b.Len)
+	})
+}
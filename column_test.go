@@ -0,0 +1,97 @@
+package lexrec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadBatches(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, ExceptRun("\n", true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexer("TestReadBatches", strings.NewReader("ab 12\nc x\nd 7\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specs := []ColumnSpec{{ItemA, StringColumn}, {ItemB, IntColumn}}
+	var names [][]string
+	var counts [][]int64
+	var valid [][]bool
+	err = ReadBatches(l, specs, 2, func(b *Batch) error {
+		names = append(names, append([]string(nil), b.Columns[0].Strings...))
+		counts = append(counts, append([]int64(nil), b.Columns[1].Ints...))
+		valid = append(valid, append([]bool(nil), b.Columns[1].Valid...))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := [][]string{{"ab", "c"}, {"d"}}; !reflect.DeepEqual(names, expect) {
+		t.Errorf("expected names %v, got %v", expect, names)
+	}
+	if expect := [][]int64{{12, 0}, {7}}; !reflect.DeepEqual(counts, expect) {
+		t.Errorf("expected counts %v, got %v", expect, counts)
+	}
+	if expect := [][]bool{{true, false}, {true}}; !reflect.DeepEqual(valid, expect) {
+		t.Errorf("expected valid %v, got %v", expect, valid)
+	}
+}
+
+// testSink is a ColumnSink recording its calls as strings.
+type testSink struct {
+	calls []string
+}
+
+func (s *testSink) AppendString(col int, v string) {
+	s.calls = append(s.calls, fmt.Sprintf("%d:%q", col, v))
+}
+
+func (s *testSink) AppendInt(col int, v int64) {
+	s.calls = append(s.calls, fmt.Sprintf("%d:%d", col, v))
+}
+
+func (s *testSink) AppendFloat(col int, v float64) {
+	s.calls = append(s.calls, fmt.Sprintf("%d:%g", col, v))
+}
+
+func (s *testSink) AppendNull(col int) {
+	s.calls = append(s.calls, fmt.Sprintf("%d:null", col))
+}
+
+func (s *testSink) Flush(rows int) error {
+	s.calls = append(s.calls, fmt.Sprintf("flush %d", rows))
+	return nil
+}
+
+func TestWriteColumns(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, ExceptRun("\n", true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexer("TestWriteColumns", strings.NewReader("ab 1.5\nc x\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &testSink{}
+	specs := []ColumnSpec{{ItemA, StringColumn}, {ItemB, FloatColumn}}
+	if err := WriteColumns(l, specs, 10, sink); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{`0:"ab"`, `0:"c"`, "1:1.5", "1:null", "flush 2"}
+	if !reflect.DeepEqual(sink.calls, expect) {
+		t.Errorf("expected %v, got %v", expect, sink.calls)
+	}
+}
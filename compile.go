@@ -0,0 +1,123 @@
+package lexrec
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// CompiledRecord is an immutable, validated copy of a Record.  A
+// CompiledRecord may be shared by any number of Lexers running
+// concurrently: its States, Names, Torn, Only and ReadSizes are private
+// copies that cannot be modified, and Compile rejects Records holding
+// per-run mutable state.
+//
+// The StateFns and other functions of the Record must themselves be
+// safe for concurrent use, which Compile cannot check.  A StateFn is
+// safe so long as it does not modify variables it shares with other
+// calls, e.g., a counter captured by its closure.  Most of those
+// provided by this package are, but not those that keep counts: the
+// Lex method of a Triage, and a StateFn returned by Sanitize, update
+// their Triage's or Sanitizer's counts, which must not be shared by
+// Lexers running concurrently.
+type CompiledRecord struct {
+	rec Record
+}
+
+// Compile validates rec and returns an immutable copy of it.  It
+// returns an error if rec has no States, a Buflen less than 1, a nil
+// ErrorFn, or a nil StateFn, or if it sets Coverage, Filter,
+// LineStats, Sketches, an Arena, a Cache, a Quarantine or a PosMap,
+// which are updated by each Lexer and so cannot be shared, or
+// ZeroCopy, which requires NewLexerSync.
+func Compile(rec Record) (*CompiledRecord, error) {
+	if len(rec.States) == 0 {
+		return nil, fmt.Errorf("rec.states must not be empty.")
+	}
+	if rec.Buflen < 1 {
+		return nil, fmt.Errorf("rec.Buflen must be > 0: %d", rec.Buflen)
+	}
+	if rec.ErrorFn == nil {
+		return nil, fmt.Errorf("rec.ErrorFn must not be nil")
+	}
+	if rec.Coverage != nil {
+		return nil, fmt.Errorf("rec.Coverage must be nil in a compiled record")
+	}
+	if rec.Filter != nil || rec.LineStats != nil || rec.Sketches != nil || rec.Arena != nil {
+		return nil, fmt.Errorf("rec.Filter, rec.LineStats, rec.Sketches and rec.Arena must be nil in a compiled record")
+	}
+	if rec.Cache != nil {
+		return nil, fmt.Errorf("rec.Cache must be nil in a compiled record")
+	}
+	if rec.Quarantine != nil || rec.PosMap != nil {
+		return nil, fmt.Errorf("rec.Quarantine and rec.PosMap must be nil in a compiled record")
+	}
+	if rec.ZeroCopy {
+		return nil, fmt.Errorf("rec.ZeroCopy requires NewLexerSync")
+	}
+	for i, b := range rec.States {
+		if b.StateFn == nil {
+			return nil, fmt.Errorf("rec.States[%d].StateFn must not be nil", i)
+		}
+	}
+	rec.States = append([]Binding(nil), rec.States...)
+	rec.copySlices()
+	if rec.Names != nil {
+		names := make(NameMap, len(rec.Names))
+		for t, name := range rec.Names {
+			names[t] = name
+		}
+		rec.Names = names
+	}
+	return &CompiledRecord{rec: rec}, nil
+}
+
+// copySlices replaces the Torn, Only and ReadSizes slices of rec with
+// copies, so that they are not shared with the Record they came from.
+func (rec *Record) copySlices() {
+	if rec.Torn != nil {
+		rec.Torn = append([]Binding(nil), rec.Torn...)
+	}
+	if rec.Only != nil {
+		rec.Only = append([]ItemType(nil), rec.Only...)
+	}
+	if rec.ReadSizes != nil {
+		rec.ReadSizes = append([]int(nil), rec.ReadSizes...)
+	}
+}
+
+// Record returns a copy of the compiled Record.  Modifying the copy
+// does not affect the CompiledRecord.
+func (c *CompiledRecord) Record() Record {
+	rec := c.rec
+	rec.States = append([]Binding(nil), c.rec.States...)
+	rec.copySlices()
+	if c.rec.Names != nil {
+		rec.Names = make(NameMap, len(c.rec.Names))
+		for t, name := range c.rec.Names {
+			rec.Names[t] = name
+		}
+	}
+	return rec
+}
+
+// NewLexer returns a lexer for the compiled Record reading from the
+// UTF-8 reader r.  The name is only used for debugging messages.
+func (c *CompiledRecord) NewLexer(name string, r io.Reader) *Lexer {
+	return c.NewLexerContext(context.Background(), name, r)
+}
+
+// NewLexerContext is like NewLexer, but returns a lexer that stops when
+// ctx is canceled, as described by the NewLexerContext function.
+func (c *CompiledRecord) NewLexerContext(ctx context.Context, name string, r io.Reader) *Lexer {
+	l := &Lexer{
+		name: name,
+		r:    r,
+		rec:  c.rec,
+		next: make([]byte, c.rec.Buflen),
+	}
+	l.parent = ctx
+	l.ctx, l.stop = context.WithCancel(ctx)
+	l.spawn()
+	return l
+}
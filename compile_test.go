@@ -0,0 +1,63 @@
+package lexrec
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCompiledRecord(t *testing.T) {
+	rejected := []struct {
+		name string
+		rec  Record
+	}{
+		{"Coverage", Record{Coverage: &Coverage{}}},
+		{"Filter", Record{Filter: &Filter{}}},
+		{"LineStats", Record{LineStats: &LineStats{}}},
+		{"Sketches", Record{Sketches: SketchMap{}}},
+		{"Arena", Record{Arena: NewByteArena(64)}},
+		{"Cache", Record{Cache: &Cache{}}},
+		{"Quarantine", Record{Quarantine: &bytes.Buffer{}}},
+		{"PosMap", Record{PosMap: PosMapFunc(func(pos int64) int64 { return pos })}},
+		{"ZeroCopy", Record{ZeroCopy: true}},
+	}
+	for _, r := range rejected {
+		r.rec.Buflen, r.rec.ErrorFn, r.rec.States = 1, SkipPast("\n"), aRecord.States
+		if _, err := Compile(r.rec); err == nil {
+			t.Errorf("expected an error compiling a Record with %s", r.name)
+		}
+	}
+
+	states := []Binding{{ItemEmit, acceptRunA, true}}
+	torn := []Binding{{ItemEmit, acceptRunA, true}}
+	only := []ItemType{ItemEmit}
+	sizes := []int{1}
+	c, err := Compile(Record{Buflen: 1, ErrorFn: SkipPast("\n"), States: states, Torn: torn, Only: only, ReadSizes: sizes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	states[0].Emit, torn[0].Emit, only[0], sizes[0] = false, false, ItemA, 2
+	if rec := c.Record(); !rec.States[0].Emit || !rec.Torn[0].Emit || rec.Only[0] != ItemEmit || rec.ReadSizes[0] != 1 {
+		t.Errorf("expected compiled Record to be unaffected by changes to its source")
+	}
+	rec := c.Record()
+	rec.Only[0] = ItemA
+	if c.Record().Only[0] != ItemEmit {
+		t.Errorf("expected compiled Record to be unaffected by changes to a copy")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l := c.NewLexer("TestCompiledRecord", strings.NewReader("aaa"))
+			if item := l.NextItem(); item.Value != "aaa" {
+				t.Errorf("expected \"aaa\", got %q", item)
+			}
+			l.Drain()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,41 @@
+package lexrec
+
+// Concat returns a Record whose States are the States of recs, in
+// order, so that shared groups of fields, such as a timestamp block,
+// can be defined once and reused by several formats.  The Buflen of
+// the result is the sum of the Buflens of recs, its Names are the
+// union of their Names, and its remaining settings, such as ErrorFn,
+// are taken from the first Record.  The States of recs are copied, not
+// shared.
+func Concat(recs ...Record) Record {
+	if len(recs) == 0 {
+		return Record{}
+	}
+	rec := recs[0]
+	rec.Buflen = 0
+	rec.States = nil
+	rec.Names = nil
+	for _, r := range recs {
+		rec.Buflen += r.Buflen
+		rec.States = append(rec.States, r.States...)
+		for t, name := range r.Names {
+			if rec.Names == nil {
+				rec.Names = make(NameMap)
+			}
+			rec.Names[t] = name
+		}
+	}
+	return rec
+}
+
+// WithErrorFn returns a copy of rec using fn as its ErrorFn.
+func WithErrorFn(rec Record, fn ErrorFn) Record {
+	rec.ErrorFn = fn
+	return rec
+}
+
+// WithBuflen returns a copy of rec using n as its Buflen.
+func WithBuflen(rec Record, n int) Record {
+	rec.Buflen = n
+	return rec
+}
@@ -0,0 +1,40 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	word := Record{
+		Buflen: 4,
+		Names:  NameMap{ItemA: "word"},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false}},
+	}
+	number := Record{
+		Buflen: 4,
+		Names:  NameMap{ItemB: "number"},
+		States: []Binding{
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	rec := WithErrorFn(Concat(word, number), SkipPast("\n"))
+	if rec.Buflen != 8 || len(rec.States) != 4 || len(rec.Names) != 2 {
+		t.Fatalf("unexpected Concat result %+v", rec)
+	}
+	if rec = WithBuflen(rec, 16); rec.Buflen != 16 {
+		t.Errorf("expected Buflen 16, got %d", rec.Buflen)
+	}
+
+	items := lexAll(t, "TestConcat", "ab 12\n", rec)
+	expect := []Item{{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemB, Pos: 3, Value: "12"}, {Type: ItemEOR, Pos: 6}, {Type: ItemEOF, Pos: 6}}
+	if len(items) != len(expect) {
+		t.Fatalf("expected %v, got %v", expect, items)
+	}
+	for i := range expect {
+		if !sameItem(items[i], expect[i]) {
+			t.Errorf("expected %v, got %v", expect[i], items[i])
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package lexrec
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errorContext returns a description of where the current error
+// occurred: the offsets of the start of the current field and of the
+// offending rune, followed by an excerpt of up to n bytes of the
+// surrounding line with a caret beneath the offending rune, e.g.:
+//
+//	 (field at 12, error at 15)
+//		10/Oct/20x0:13:55:36
+//		         ^
+func (l *Lexer) errorContext(n int) string {
+	from, to := l.pos-n/2, l.pos+n/2
+	if from < 0 {
+		from = 0
+	}
+	if to > len(l.buf) {
+		to = len(l.buf)
+	}
+	// stay within the current line, and on rune boundaries.
+	if i := strings.LastIndexAny(string(l.buf[from:l.pos]), "\r\n"); i >= 0 {
+		from += i + 1
+	}
+	if i := strings.IndexAny(string(l.buf[l.pos:to]), "\r\n"); i >= 0 {
+		to = l.pos + i
+	}
+	for from < l.pos && !utf8.RuneStart(l.buf[from]) {
+		from++
+	}
+	for to > l.pos && to < len(l.buf) && !utf8.RuneStart(l.buf[to]) {
+		to--
+	}
+	excerpt := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, string(l.buf[from:to]))
+	caret := utf8.RuneCount(l.buf[from:l.pos])
+	return fmt.Sprintf(" (field at %d, error at %d)\n\t%s\n\t%s^", l.tokenPos(), l.rpos, excerpt, strings.Repeat(" ", caret))
+}
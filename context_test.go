@@ -0,0 +1,29 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestErrorContext(t *testing.T) {
+	rec := Record{
+		Buflen:  64,
+		ErrorFn: SkipPast("\n"),
+		Context: 16,
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Int(0, 99999), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestErrorContext", "ok 1\nab 12x4 more and more\n", rec)
+	var item Item
+	for _, item = range items {
+		if item.Type == ItemError {
+			break
+		}
+	}
+	expect := "bad integer syntax: \"12x\" (field at 8, error at 11)\n\tab 12x4 more a\n\t      ^"
+	if item.Value != expect {
+		t.Errorf("expected %q, got %q", expect, item.Value)
+	}
+}
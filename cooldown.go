@@ -0,0 +1,33 @@
+package lexrec
+
+// Cooldown configures a cheaper form of recovery for sections of
+// garbage in the input.  Once After consecutive records have failed,
+// the Lexer stops lexing record by record and instead scans forward,
+// trying only the positions that follow one of the Terminators, until
+// it finds the start of a clean record or has skipped Bytes bytes.
+// Full lexing then resumes.
+type Cooldown struct {
+	After       int       // number of consecutive failed records that start a cooldown
+	Bytes       int64     // if > 0, resume full lexing after skipping this many bytes
+	Terminators string    // runes that end a record; if empty the Record's Terminator is used
+	Sig         []Binding // bindings a clean record must match; nil means the Record's States
+}
+
+// cooldown scans forward from the current position as configured by
+// c.  The scan ends at the first candidate position at which the
+// bindings of c.Sig all succeed, or, if c.Bytes > 0, at the first
+// candidate position at least c.Bytes bytes on.
+func (l *Lexer) cooldown(c *Cooldown) {
+	sig := c.Sig
+	if sig == nil {
+		sig = l.rec.States
+	}
+	from := l.tokenPos()
+	for c.Bytes <= 0 || l.tokenPos()-from < c.Bytes {
+		if l.Peek() == EOF || l.try(sig) {
+			return
+		}
+		l.boundary(c.Terminators)
+		l.Skip()
+	}
+}
@@ -0,0 +1,59 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCooldown(t *testing.T) {
+	rec := Record{
+		Buflen:    16,
+		ErrorFn:   SkipPast("\n"),
+		Recovered: true,
+		Cooldown:  &Cooldown{After: 2, Terminators: "\n"},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestCooldown", "ab\n1\n2\n3\n4\ncd\n5\nef\n", rec)
+	var got []string
+	var recovered []string
+	for _, item := range items {
+		switch item.Type {
+		case ItemA:
+			got = append(got, item.Value)
+		case ItemRecovered:
+			recovered = append(recovered, item.Value)
+		}
+	}
+	if strings.Join(got, ",") != "ab,cd,ef" {
+		t.Errorf("expected items ab,cd,ef, got %v", got)
+	}
+	// the second failure starts a cooldown that skips "3\n4\n"
+	// without attempting the records.
+	if strings.Join(recovered, ",") != "2,6,2" {
+		t.Errorf("expected recoveries of 2,6,2 bytes, got %v", recovered)
+	}
+}
+
+func TestCooldownBytes(t *testing.T) {
+	rec := Record{
+		Buflen:    16,
+		ErrorFn:   SkipPast("\n"),
+		Recovered: true,
+		Cooldown:  &Cooldown{After: 1, Bytes: 3, Terminators: "\n"},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestCooldownBytes", "1\n2\n3\n4\nab\n", rec)
+	var recovered []string
+	for _, item := range items {
+		if item.Type == ItemRecovered {
+			recovered = append(recovered, item.Value)
+		}
+	}
+	if strings.Join(recovered, ",") != "6,2" {
+		t.Errorf("expected recoveries of 6,2 bytes, got %v", recovered)
+	}
+}
@@ -0,0 +1,140 @@
+package lexrec
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BindingStats counts the outcomes of calls to a Binding's StateFn.
+type BindingStats struct {
+	Success int64 // calls that succeeded, including those counted by Empty
+	Failure int64 // calls that failed
+	Empty   int64 // calls that succeeded without consuming any input
+}
+
+// BindingTiming holds the time taken by the sampled calls to a
+// Binding's StateFn.
+type BindingTiming struct {
+	Calls int64         // calls timed
+	Time  time.Duration // total time taken by the timed calls
+}
+
+// Mean returns the mean time taken by a timed call, or 0 if none were
+// timed.
+func (t BindingTiming) Mean() time.Duration {
+	if t.Calls == 0 {
+		return 0
+	}
+	return t.Time / time.Duration(t.Calls)
+}
+
+// Coverage reports how often each Binding of a Record succeeded,
+// failed, and matched empty, so that format authors can find dead
+// branches and hot failure points.  If Sample is > 0, one call in
+// every Sample to each Binding is also timed, cheaply enough to leave
+// on, so that the Bindings that dominate the time spent lexing can be
+// found without an external profiler.  Set a Record's Coverage to a
+// *Coverage to collect it.  The counts are updated by the Lexer's
+// goroutine, and are only safe to read once ItemEOF has been received.
+type Coverage struct {
+	States  []BindingStats  // outcomes, indexed by position in the Record's States
+	Sample  int             // if > 0, time one in every Sample calls to each Binding
+	Timings []BindingTiming // times of the sampled calls, indexed like States
+}
+
+// call runs the StateFn of b, the i'th Binding of the Record,
+// recording its outcome in the Record's Coverage.  While it runs,
+// errors are attributed to the Binding.
+func (l *Lexer) call(i int, b Binding) bool {
+	l.running = i + 1
+	defer func() { l.running = 0 }()
+	c := l.rec.Coverage
+	if c == nil {
+		return b.StateFn(l, b.ItemType, b.Emit)
+	}
+	for len(c.States) <= i {
+		c.States = append(c.States, BindingStats{})
+	}
+	s := &c.States[i]
+	timed := c.Sample > 0 && (s.Success+s.Failure)%int64(c.Sample) == 0
+	var begin time.Time
+	if timed {
+		begin = time.Now()
+	}
+	from := l.tokenPos()
+	success := b.StateFn(l, b.ItemType, b.Emit)
+	if timed {
+		for len(c.Timings) <= i {
+			c.Timings = append(c.Timings, BindingTiming{})
+		}
+		c.Timings[i].Calls++
+		c.Timings[i].Time += time.Since(begin)
+	}
+	switch {
+	case !success:
+		s.Failure++
+	case l.rpos == from:
+		s.Success++
+		s.Empty++
+	default:
+		s.Success++
+	}
+	return success
+}
+
+// Report returns a table of the counts for each of rec's Bindings,
+// one line per Binding, labeled by index, item type name and matcher.
+// If calls were sampled, the table also holds the mean time of a call
+// and each Binding's estimated share of the total time.
+func (c *Coverage) Report(rec Record) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%5s %-24s %10s %10s %10s", "state", "binding", "success", "failure", "empty")
+	if c.Sample > 0 {
+		fmt.Fprintf(&sb, " %10s %6s", "mean", "share")
+	}
+	sb.WriteString("\n")
+	total := c.estimate(-1)
+	for _, info := range rec.Describe() {
+		var s BindingStats
+		if info.Index < len(c.States) {
+			s = c.States[info.Index]
+		}
+		name := info.Name
+		if name == "" {
+			name = info.Matcher
+		}
+		fmt.Fprintf(&sb, "%5d %-24s %10d %10d %10d", info.Index, name, s.Success, s.Failure, s.Empty)
+		if c.Sample > 0 {
+			var mean time.Duration
+			if info.Index < len(c.Timings) {
+				mean = c.Timings[info.Index].Mean()
+			}
+			share := 0.0
+			if total > 0 {
+				share = 100 * c.estimate(info.Index) / total
+			}
+			fmt.Fprintf(&sb, " %10s %5.1f%%", mean, share)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// estimate returns the estimated total time, in nanoseconds, spent in
+// calls to the i'th Binding or, if i < 0, to all of them: the mean time
+// of a timed call multiplied by the number of calls.
+func (c *Coverage) estimate(i int) float64 {
+	if i < 0 {
+		total := 0.0
+		for i := range c.Timings {
+			total += c.estimate(i)
+		}
+		return total
+	}
+	if i >= len(c.Timings) || i >= len(c.States) {
+		return 0
+	}
+	s := c.States[i]
+	return float64(c.Timings[i].Mean()) * float64(s.Success+s.Failure)
+}
@@ -0,0 +1,81 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCoverage(t *testing.T) {
+	c := &Coverage{}
+	optionalSpaces := func(l *Lexer, t ItemType, emit bool) bool {
+		l.AcceptRun(" ")
+		l.Skip()
+		return true
+	}
+	rec := Record{
+		Buflen:   16,
+		ErrorFn:  SkipPast("\n"),
+		Coverage: c,
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, optionalSpaces, false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	lexAll(t, "TestCoverage", "a 1\nb2\nc x\n", rec)
+	expect := []BindingStats{
+		{3, 0, 0},
+		{3, 0, 1},
+		{2, 1, 0},
+		{2, 0, 0},
+	}
+	if len(c.States) != len(expect) {
+		t.Fatalf("expected %v, got %v", expect, c.States)
+	}
+	for i := range expect {
+		if c.States[i] != expect[i] {
+			t.Errorf("state %d: expected %+v, got %+v", i, expect[i], c.States[i])
+		}
+	}
+}
+
+func TestCoverageSample(t *testing.T) {
+	c := &Coverage{Sample: 2}
+	slow := func(l *Lexer, t ItemType, emit bool) bool {
+		time.Sleep(time.Millisecond)
+		return Digits(l, t, emit)
+	}
+	rec := Record{
+		Buflen:   16,
+		ErrorFn:  SkipPast("\n"),
+		Coverage: c,
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, slow, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	lexAll(t, "TestCoverageSample", strings.Repeat("a 1\n", 5), rec)
+	if len(c.Timings) != len(rec.States) {
+		t.Fatalf("expected %d timings, got %v", len(rec.States), c.Timings)
+	}
+	for i, timing := range c.Timings {
+		if timing.Calls != 3 {
+			t.Errorf("state %d: expected 3 of 5 calls timed, got %d", i, timing.Calls)
+		}
+	}
+	if c.Timings[2].Mean() < time.Millisecond {
+		t.Errorf("expected a mean of at least 1ms, got %v", c.Timings[2].Mean())
+	}
+	if share := 100 * c.estimate(2) / c.estimate(-1); share < 50 {
+		t.Errorf("expected the slow binding to dominate, got a share of %.1f%%", share)
+	}
+	if report := c.Report(rec); !strings.Contains(report, "share") {
+		t.Errorf("expected a report with timings, got %s", report)
+	}
+	c.Sample = 0
+	if report := c.Report(rec); strings.Contains(report, "share") {
+		t.Errorf("expected a report without timings, got %s", report)
+	}
+}
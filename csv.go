@@ -0,0 +1,58 @@
+package lexrec
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// WriteCSV reads records from l until ItemEOF, writing the values of
+// the fields of type fields, in the order given, as RFC 4180 CSV rows
+// to w.  Fields missing from a record are written as empty values.  If
+// header is true a header row is written first, using the Record's
+// Names, or the numeric ItemType for unnamed fields.  Records that
+// fail to lex are skipped.  WriteCSV returns the number of records
+// written and the first error returned by w.
+func WriteCSV(l *Lexer, w io.Writer, fields []ItemType, header bool) (records int, err error) {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	row := make([]string, len(fields))
+	if header {
+		for i, t := range fields {
+			name, ok := l.rec.Names[t]
+			if !ok {
+				name = strconv.Itoa(int(t))
+			}
+			row[i] = name
+		}
+		if err = cw.Write(row); err != nil {
+			return
+		}
+	}
+	index := make(map[ItemType]int, len(fields))
+	for i, t := range fields {
+		index[t] = i
+	}
+	for {
+		items, failed, eof := readRecord(l)
+		if !failed && len(items) > 0 {
+			for i := range row {
+				row[i] = ""
+			}
+			for _, item := range items {
+				if i, ok := index[item.Type]; ok {
+					row[i] = item.Value
+				}
+			}
+			if err = cw.Write(row); err != nil {
+				return
+			}
+			records++
+		}
+		if eof {
+			cw.Flush()
+			err = cw.Error()
+			return
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package lexrec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Names:   NameMap{ItemA: "name", ItemB: "note"},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, ExceptRun("\n", true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexer("TestWriteCSV", strings.NewReader("ab x,y\nc \"q\"\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	n, err := WriteCSV(l, buf, []ItemType{ItemB, ItemA}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 records, got %d", n)
+	}
+	expect := "note,name\r\n\"x,y\",ab\r\n\"\"\"q\"\"\",c\r\n"
+	if buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+}
@@ -0,0 +1,46 @@
+package lexrec
+
+import (
+	"fmt"
+)
+
+// Delimited returns a StateFn that, like Quote, consumes a field
+// enclosed by the runes open and close, e.g., Delimited('[', ']', 0,
+// false) or Delimited('(', ')', '\\', true).  Within the field, a
+// rune following escape is consumed without being interpreted, so that
+// an escaped close, escape or newline does not end it; an escape of 0
+// means there is none.  Unescaped newlines are allowed only if
+// newlines is true.  The delimiters are included in the item's value.
+// An error is emitted if the field does not begin with open, or if the
+// end of the line, or of the input, is reached before close.
+func Delimited(open, close, escape rune, newlines bool) StateFn {
+	expected := fmt.Sprintf("%q", open)
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		r := l.Next()
+		if r != open {
+			l.Fail(&UnexpectedRuneError{Pos: l.rpos, Rune: r, Expected: expected})
+			l.Backup()
+			return false
+		}
+		for {
+			switch r := l.Next(); {
+			case r == EOF:
+				l.Fail(&UnterminatedQuoteError{Pos: l.tokenPos()})
+				return false
+			case r == escape && escape != 0:
+				l.Next()
+			case r == close:
+				if emit {
+					l.Emit(t)
+				} else {
+					l.Skip()
+				}
+				return true
+			case r == '\n' && !newlines:
+				l.Fail(&UnterminatedQuoteError{Pos: l.tokenPos()})
+				l.Backup()
+				return false
+			}
+		}
+	}
+}
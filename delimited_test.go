@@ -0,0 +1,37 @@
+package lexrec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDelimited(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Delimited('[', ']', 0, false), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Delimited('\'', '\'', '\\', true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	input := "[a b] 'c\\'d'\n[é] 'x\ny'\n(a) 'z'\n[a\n"
+	items := lexAll(t, "TestDelimited", input, rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: "[a b]"}, {Type: ItemB, Pos: 6, Value: `'c\'d'`}, {Type: ItemEOR, Pos: 13},
+		{Type: ItemA, Pos: 13, Value: "[é]"}, {Type: ItemB, Pos: 18, Value: "'x\ny'"}, {Type: ItemEOR, Pos: 24},
+		{Type: ItemError, Pos: 24},
+		{Type: ItemError, Pos: 32},
+		{Type: ItemEOF, Pos: 35}})
+	if got := summarize(items); got != expect {
+		t.Fatalf("expected %s, got %s", expect, got)
+	}
+	var ure *UnexpectedRuneError
+	if !errors.As(items[6].Err.Cause, &ure) || ure.Rune != '(' {
+		t.Errorf("expected an *UnexpectedRuneError for '(', got %v", items[6].Err.Cause)
+	}
+	var uqe *UnterminatedQuoteError
+	if !errors.As(items[7].Err.Cause, &uqe) || uqe.Pos != 32 {
+		t.Errorf("expected an *UnterminatedQuoteError at 32, got %v", items[7].Err.Cause)
+	}
+}
@@ -0,0 +1,98 @@
+package lexrec
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// StateInfo describes one Binding of a Record.
+type StateInfo struct {
+	Index    int      `json:"index"`          // position of the Binding in the Record's States
+	ItemType ItemType `json:"itemType"`       // item type of the Binding
+	Name     string   `json:"name,omitempty"` // name of the item type from the Record's Names
+	Emit     bool     `json:"emit"`           // whether the item is emitted
+	Matcher  string   `json:"matcher"`        // name of the StateFn, e.g., "lexrec.AcceptRun"
+}
+
+// Describe returns a description of each of the Record's Bindings.
+// StateFns returned by a factory such as AcceptRun are described by
+// the factory's name.
+func (rec Record) Describe() []StateInfo {
+	info := make([]StateInfo, len(rec.States))
+	for i, b := range rec.States {
+		name, _ := rec.name(b.ItemType)
+		info[i] = StateInfo{
+			Index:    i,
+			ItemType: b.ItemType,
+			Name:     name,
+			Emit:     b.Emit,
+			Matcher:  funcName(b.StateFn),
+		}
+	}
+	return info
+}
+
+// JSON returns the Record's Describe output encoded as JSON.
+func (rec Record) JSON() ([]byte, error) {
+	return json.MarshalIndent(rec.Describe(), "", "  ")
+}
+
+// Dot returns a Graphviz description of the Record's state sequence.
+// Each Binding is a node labeled with its index, item type name and
+// matcher; skipped (non-emitting) Bindings are drawn dashed.  Edges
+// lead from each state to the next, from every state to the ErrorFn
+// on failure, and from the last state back to the first.
+func (rec Record) Dot() string {
+	var sb strings.Builder
+	sb.WriteString("digraph record {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, s := range rec.Describe() {
+		name := s.Name
+		if name == "" {
+			name = s.ItemType.String()
+		}
+		style := "solid"
+		if !s.Emit {
+			style = "dashed"
+		}
+		fmt.Fprintf(&sb, "\ts%d [label=%q, style=%s];\n", s.Index, fmt.Sprintf("%d: %s\n%s", s.Index, name, s.Matcher), style)
+	}
+	fmt.Fprintf(&sb, "\terror [label=%q, shape=octagon];\n", "ErrorFn\n"+funcName(rec.ErrorFn))
+	for i := range rec.States {
+		next := i + 1
+		if next == len(rec.States) {
+			fmt.Fprintf(&sb, "\ts%d -> s0 [label=\"EOR\"];\n", i)
+		} else {
+			fmt.Fprintf(&sb, "\ts%d -> s%d;\n", i, next)
+		}
+		fmt.Fprintf(&sb, "\ts%d -> error [style=dotted];\n", i)
+	}
+	if len(rec.States) > 0 {
+		sb.WriteString("\terror -> s0 [style=dotted];\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// funcName returns the name of the function fn, trimmed of its import
+// path and of any closure suffix, or "nil" if fn is nil.
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if !v.IsValid() || v.IsNil() {
+		return "nil"
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	name := f.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, ".func"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,34 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecordDescribe(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Names:   NameMap{ItemA: "word"},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	info := rec.Describe()
+	expect := []StateInfo{
+		{0, ItemA, "word", true, "lexrec.Letters"},
+		{1, ItemIgnore, "", false, "lexrec.Accept"},
+	}
+	for i := range expect {
+		if info[i] != expect[i] {
+			t.Errorf("expected %+v, got %+v", expect[i], info[i])
+		}
+	}
+
+	dot := rec.Dot()
+	for _, s := range []string{`s0 [label="0: word\nlexrec.Letters", style=solid]`, "s1 -> s0", `ErrorFn\nlexrec.SkipPast`} {
+		if !strings.Contains(dot, s) {
+			t.Errorf("expected Dot output to contain %q:\n%s", s, dot)
+		}
+	}
+}
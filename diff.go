@@ -0,0 +1,137 @@
+package lexrec
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DiffKind describes how a record differs between two inputs.
+type DiffKind int
+
+const (
+	OnlyInA DiffKind = iota // record appears only in the first input
+	OnlyInB                 // record appears only in the second input
+	Changed                 // record appears in both inputs with differing fields
+)
+
+// Difference describes a record that differs between two inputs.
+type Difference struct {
+	Kind DiffKind
+	Key  string // key identifying the record
+	A    []Item // items of the record in the first input, nil if OnlyInB
+	B    []Item // items of the record in the second input, nil if OnlyInA
+}
+
+// Diff lexes a and b using rec and calls fn for each record that
+// differs between them.  Records are matched by the values of the
+// fields of type key, joined by NUL; if key is empty, records are
+// matched by the types and values of all their items, so that only
+// OnlyInA and OnlyInB differences are reported.  Records sharing a key
+// are matched in input order.  OnlyInA and Changed differences are
+// reported in the order of a, followed by OnlyInB differences in the
+// order of b.  Records that fail to lex are ignored.  The records of b
+// are held in memory.  Diff returns the first error returned by fn.
+func Diff(a, b io.Reader, rec Record, key []ItemType, fn func(d Difference) error) error {
+	lb, err := NewLexer("b", b, rec)
+	if err != nil {
+		return err
+	}
+	defer lb.Close()
+	type entry struct {
+		items   []Item
+		matched bool
+	}
+	var order []*entry
+	byKey := make(map[string][]*entry)
+	for {
+		items, failed, eof := readRecord(lb)
+		if !failed && len(items) > 0 {
+			e := &entry{items: items}
+			k := recordKey(items, key)
+			byKey[k] = append(byKey[k], e)
+			order = append(order, e)
+		}
+		if eof {
+			break
+		}
+	}
+
+	la, err := NewLexer("a", a, rec)
+	if err != nil {
+		return err
+	}
+	defer la.Close()
+	for {
+		items, failed, eof := readRecord(la)
+		if !failed && len(items) > 0 {
+			k := recordKey(items, key)
+			d := Difference{Kind: OnlyInA, Key: k, A: items}
+			if matches := byKey[k]; len(matches) > 0 {
+				e := matches[0]
+				byKey[k] = matches[1:]
+				e.matched = true
+				d.Kind, d.B = Changed, e.items
+			}
+			if d.B == nil || !sameItems(d.A, d.B) {
+				if err := fn(d); err != nil {
+					return err
+				}
+			}
+		}
+		if eof {
+			break
+		}
+	}
+
+	for _, e := range order {
+		if !e.matched {
+			if err := fn(Difference{Kind: OnlyInB, Key: recordKey(e.items, key), B: e.items}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordKey returns the key of a record, the values of the items of
+// type key joined by NUL, or if key is empty, a fingerprint of all of
+// its item types and values.
+func recordKey(items []Item, key []ItemType) string {
+	var sb strings.Builder
+	if len(key) == 0 {
+		for _, item := range items {
+			sb.WriteString(strconv.Itoa(int(item.Type)))
+			sb.WriteByte(':')
+			sb.WriteString(item.Value)
+			sb.WriteByte(0)
+		}
+		return sb.String()
+	}
+	for i, t := range key {
+		if i > 0 {
+			sb.WriteByte(0)
+		}
+		for _, item := range items {
+			if item.Type == t {
+				sb.WriteString(item.Value)
+				break
+			}
+		}
+	}
+	return sb.String()
+}
+
+// sameItems reports whether two records hold the same item types and
+// values, ignoring their positions.
+func sameItems(a, b []Item) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
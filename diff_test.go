@@ -0,0 +1,68 @@
+package lexrec
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	a := "x 1\ny 2\nz 3\n"
+	b := "w 0\nx 1\ny 9\n"
+
+	type result struct {
+		kind DiffKind
+		key  string
+	}
+	var got []result
+	err := Diff(strings.NewReader(a), strings.NewReader(b), rec, []ItemType{ItemA}, func(d Difference) error {
+		got = append(got, result{d.Kind, d.Key})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []result{{Changed, "y"}, {OnlyInA, "z"}, {OnlyInB, "w"}}
+	if len(got) != len(expect) {
+		t.Fatalf("expected %v, got %v", expect, got)
+	}
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Errorf("expected %v, got %v", expect[i], got[i])
+		}
+	}
+}
+
+func TestDiffStop(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	a := strings.Repeat("x\n", 1000)
+	stop := errors.New("stop")
+	before := runtime.NumGoroutine()
+	calls := 0
+	err := Diff(strings.NewReader(a), strings.NewReader(""), rec, []ItemType{ItemA}, func(d Difference) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("expected the error of the first call, got %v after %d calls", err, calls)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected the lexers' goroutines to have finished, %d running before, %d after", before, after)
+	}
+}
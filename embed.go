@@ -0,0 +1,69 @@
+package lexrec
+
+import (
+	"strings"
+)
+
+// Embed returns a StateFn for a field holding records of an embedded
+// format, such as a quoted field that is itself a comma-separated
+// list.  The field is consumed by fn, and its value is then lexed as
+// rec records.  The items of the embedded records are emitted in
+// place of the field, with ns added to their ItemType to keep them
+// apart from the item types of the enclosing Record, and with their
+// positions offset by that of the field, which is only exact if fn
+// emits the bytes it consumed.  The embedded ItemEOR and ItemEOF items
+// are dropped.  An error in the embedded records fails the field.
+// The enclosing Record's Only set, and the buffers of ScanRecord,
+// apply to the embedded items by their own, offset, ItemTypes: the
+// embedded items are delivered whether or not t itself would be.
+// For example, for a field holding a comma-separated list of tags,
+// lexed by a tags Record whose ItemTag is ItemEOF+1:
+//
+//	const tagNS = 100 // embedded items are emitted as ItemTag+tagNS
+//	{ItemIgnore, lexrec.Embed(lexrec.ExceptRun(" ", true), tags, tagNS), true}
+//
+// While StateFns are being tried, e.g., by Sync, only fn is run.
+func Embed(fn StateFn, rec Record, ns ItemType) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		var fields []Item
+		prev := l.capture
+		l.capture = func(item Item) {
+			fields = append(fields, item)
+		}
+		success := fn(l, t, true)
+		l.capture = prev
+		if !success {
+			return false
+		}
+		for _, field := range fields {
+			if !l.embed(field, rec, ns, emit) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// embed lexes the value of field as rec records, emitting their items
+// if emit is true, and reporting whether they were lexed without
+// error.
+func (l *Lexer) embed(field Item, rec Record, ns ItemType, emit bool) bool {
+	sub, err := NewLexerSync(l.name, strings.NewReader(field.Value), rec)
+	if err != nil {
+		l.Errorf("%s: embedded record: %v", l.name, err)
+		return false
+	}
+	for sub.Scan() {
+		item := sub.Item()
+		switch {
+		case item.Type == ItemError:
+			l.Fail(item.cause())
+			return false
+		case item.Type > ItemEOF && emit:
+			item.Type += ns
+			item.Pos += field.Pos
+			l.emit(item)
+		}
+	}
+	return true
+}
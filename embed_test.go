@@ -0,0 +1,81 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbed(t *testing.T) {
+	const ns = 100
+	tags := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast(","),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(",", false), false}},
+	}
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Embed(ExceptRun("\n", true), tags, ns), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestEmbed", "ab x,y,zz\ncd p,1\nef q\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemA + ns, Pos: 3, Value: "x"}, {Type: ItemA + ns, Pos: 5, Value: "y"}, {Type: ItemA + ns, Pos: 7, Value: "zz"}, {Type: ItemEOR, Pos: 10},
+		{Type: ItemA, Pos: 10, Value: "cd"}, {Type: ItemA + ns, Pos: 13, Value: "p"}, {Type: ItemError, Pos: 16},
+		{Type: ItemA, Pos: 17, Value: "ef"}, {Type: ItemA + ns, Pos: 20, Value: "q"}, {Type: ItemEOR, Pos: 22},
+		{Type: ItemEOF, Pos: 22}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range expect {
+		if expect[i].Type != ItemError && items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+}
+
+func TestEmbedOnlyAndBuffers(t *testing.T) {
+	const ns = 100
+	tags := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast(","),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(",", false), false}},
+	}
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Only:    []ItemType{ItemA + ns},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Embed(ExceptRun("\n", true), tags, ns), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestEmbedOnlyAndBuffers", "ab x,yy\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemA + ns, Pos: 3, Value: "x"}, {Type: ItemA + ns, Pos: 5, Value: "yy"}, {Type: ItemEOR, Pos: 8},
+		{Type: ItemEOF, Pos: 8}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+
+	rec.Only = nil
+	l, err := NewLexerSync("TestEmbedOnlyAndBuffers", strings.NewReader("ab x,yy\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, tag := []byte{}, []byte{}
+	if !l.ScanRecord(Buffers{ItemA: &a, ItemA + ns: &tag}) {
+		t.Fatalf("expected a record")
+	}
+	if l.Item().Type != ItemEOR || string(a) != "ab" || string(tag) != "yy" {
+		t.Errorf("expected ItemEOR \"ab\" \"yy\", got %v %q %q", l.Item().Type, a, tag)
+	}
+}
@@ -0,0 +1,40 @@
+package lexrec
+
+// EmptyAction is what the Lexer does with an empty record: one that
+// consists of nothing but its terminator, e.g., a blank line.
+type EmptyAction int
+
+const (
+	EmptyLex   EmptyAction = iota // lex it like any other record, leaving its StateFns to accept or reject it
+	EmptySkip                     // skip it, emitting nothing
+	EmptyEOR                      // emit an ItemEOR for it, as for a record without fields
+	EmptyError                    // emit an error for it, without running ErrorFn
+)
+
+// empty applies the Record's EmptyAction to an empty record at the
+// current position, reporting whether there was one.  A record is
+// empty if it begins with the Record's Terminator or, if it has none,
+// a newline.
+func (l *Lexer) empty() bool {
+	term := l.rec.Terminator
+	if term == nil {
+		term = newline
+	}
+	start := l.tokenPos()
+	if !term(l) {
+		return false
+	}
+	switch l.rec.Empty {
+	case EmptySkip:
+		l.Skip()
+		return true
+	case EmptyEOR:
+		l.Skip()
+		l.Emit(ItemEOR)
+	case EmptyError:
+		l.Skip()
+		l.Fail(&SyntaxError{Pos: start, Msg: "empty record"})
+	}
+	l.span(start, l.rec.Empty == EmptyError)
+	return true
+}
@@ -0,0 +1,77 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestEmptyInput(t *testing.T) {
+	for _, action := range []EmptyAction{EmptyLex, EmptySkip, EmptyEOR, EmptyError} {
+		rec := Record{
+			Buflen:  16,
+			ErrorFn: SkipPast("\n"),
+			Empty:   action,
+			States: []Binding{
+				{ItemA, Letters, true},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		items := lexAll(t, "TestEmptyInput", "", rec)
+		if len(items) != 1 || items[0].Type != ItemEOF {
+			t.Errorf("%d: expected only ItemEOF, got %v", action, items)
+		}
+	}
+}
+
+func TestEmptyRecord(t *testing.T) {
+	input := "ab\n\n\ncd\n\n"
+	tests := []struct {
+		action EmptyAction
+		expect []Item
+	}{
+		{EmptyLex, []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 3},
+			{Type: ItemError, Pos: 3},
+			{Type: ItemA, Pos: 5, Value: "cd"}, {Type: ItemEOR, Pos: 8},
+			{Type: ItemError, Pos: 8},
+			{Type: ItemEOF, Pos: 9}}},
+		{EmptySkip, []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 3},
+			{Type: ItemA, Pos: 5, Value: "cd"}, {Type: ItemEOR, Pos: 8},
+			{Type: ItemEOF, Pos: 9}}},
+		{EmptyEOR, []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 3},
+			{Type: ItemEOR, Pos: 4},
+			{Type: ItemEOR, Pos: 5},
+			{Type: ItemA, Pos: 5, Value: "cd"}, {Type: ItemEOR, Pos: 8},
+			{Type: ItemEOR, Pos: 9},
+			{Type: ItemEOF, Pos: 9}}},
+		{EmptyError, []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 3},
+			{Type: ItemError, Pos: 4},
+			{Type: ItemError, Pos: 5},
+			{Type: ItemA, Pos: 5, Value: "cd"}, {Type: ItemEOR, Pos: 8},
+			{Type: ItemError, Pos: 9},
+			{Type: ItemEOF, Pos: 9}}},
+	}
+	for _, test := range tests {
+		var spans []Span
+		rec := Record{
+			Buflen:  16,
+			ErrorFn: SkipPast("\n"),
+			Empty:   test.action,
+			OnSpan:  func(s Span) { spans = append(spans, s) },
+			States: []Binding{
+				{ItemA, Letters, true},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		items := lexAll(t, "TestEmptyRecord", input, rec)
+		if got, expect := summarize(items), summarize(test.expect); got != expect {
+			t.Errorf("%d: expected %s, got %s", test.action, expect, got)
+		}
+		// with EmptyLex, ErrorFn skips both of the blank lines in
+		// the middle as one record.
+		records := map[EmptyAction]int{EmptyLex: 4, EmptySkip: 2, EmptyEOR: 5, EmptyError: 5}[test.action]
+		if len(spans) != records {
+			t.Errorf("%d: expected %d spans, got %d", test.action, records, len(spans))
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package lexrec
+
+// Epoch returns a StateFn that consumes a UNIX epoch timestamp, either
+// in seconds, of up to 10 digits with an optional fraction of up to 9,
+// e.g., "1700000000.123", or in the fixed-width forms with 13 digits
+// for milliseconds, 16 for microseconds and 19 for nanoseconds.  The
+// resolution detected is reported by the item's type: the Binding's
+// type for seconds, and millis, micros or nanos for the others, or
+// the Binding's type if that is ItemError, e.g.:
+//
+//	{ItemTime, lexrec.Epoch(ItemTimeMillis, ItemTimeMicros, ItemError), true}
+//
+// Any other number of digits, or a fraction on a fixed-width form, is
+// an error.
+func Epoch(millis, micros, nanos ItemType) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		if !acceptDigits(l) {
+			l.unexpected("epoch timestamp")
+			return false
+		}
+		digits := l.Size()
+		typ := t
+		switch {
+		case digits <= 10:
+			if l.Accept(".") {
+				n := l.Size()
+				if !acceptDigits(l) || l.Size()-n > 9 {
+					l.Errorf("bad epoch fraction: %q", l.Bytes())
+					return false
+				}
+			}
+		case digits == 13:
+			typ = millis
+		case digits == 16:
+			typ = micros
+		case digits == 19:
+			typ = nanos
+		default:
+			l.Errorf("epoch timestamp %q has %d digits, expected at most 10, or 13, 16 or 19", l.Bytes(), digits)
+			return false
+		}
+		if typ == ItemError {
+			typ = t
+		}
+		if r := l.Peek(); r == '.' || l.isAlphaNumeric(r) {
+			l.Next()
+			l.Errorf("bad epoch timestamp syntax: %q", l.Bytes())
+			return false
+		}
+		if emit {
+			l.Emit(typ)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
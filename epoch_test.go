@@ -0,0 +1,41 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestEpoch(t *testing.T) {
+	rec := Record{
+		Buflen:  8,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Epoch(ItemB, ItemAorB, ItemError), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	tests := []struct {
+		input string
+		typ   ItemType
+	}{
+		{"1700000000", ItemA},
+		{"1700000000.5", ItemA},
+		{"1700000000.123456789", ItemA},
+		{"0", ItemA},
+		{"1700000000123", ItemB},
+		{"1700000000123456", ItemAorB},
+		{"1700000000123456789", ItemA},
+		{"1700000000.1234567890", ItemError},
+		{"1700000000.", ItemError},
+		{"17000000001", ItemError},
+		{"1700000000123.5", ItemError},
+		{"1700000000x", ItemError},
+		{"x", ItemError},
+	}
+	for _, test := range tests {
+		items := lexAll(t, "TestEpoch", test.input+"\n", rec)
+		if items[0].Type != test.typ {
+			t.Errorf("%q: expected type %d, got %v", test.input, test.typ, items[0])
+		} else if test.typ != ItemError && items[0].Value != test.input {
+			t.Errorf("%q: expected the whole timestamp, got %q", test.input, items[0].Value)
+		}
+	}
+}
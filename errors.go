@@ -0,0 +1,69 @@
+package lexrec
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SyntaxError is the cause of an error reported by a StateFn with
+// Errorf: a malformed record.
+type SyntaxError struct {
+	Pos int64  // position, in bytes, at which the error was detected
+	Msg string // description of the error
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Msg
+}
+
+// UnexpectedRuneError is the cause of an error reported by a StateFn
+// that found a rune other than one it expected: a malformed record.
+type UnexpectedRuneError struct {
+	Pos      int64  // position, in bytes, at which the error was detected
+	Rune     rune   // the rune found, or EOF
+	Expected string // description of what was expected, e.g., "letter"
+}
+
+func (e *UnexpectedRuneError) Error() string {
+	return fmt.Sprintf("expected %s, got %q", e.Expected, e.Rune)
+}
+
+// UnterminatedQuoteError is the cause of an error reported by Quote,
+// Delimited or Escaped when the end of the line or of the input is
+// reached before the closing quote: a malformed record.
+type UnterminatedQuoteError struct {
+	Pos int64 // position, in bytes, of the opening quote
+}
+
+func (e *UnterminatedQuoteError) Error() string {
+	return "unterminated quote"
+}
+
+// ReadError is the cause of an error reported when the Lexer's reader
+// fails: an I/O failure rather than a malformed record.
+type ReadError struct {
+	Name string // name of the input
+	Err  error  // the error returned by the reader
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// unexpected reports that the next rune is not the one expected.
+func (l *Lexer) unexpected(expected string) {
+	l.Fail(&UnexpectedRuneError{Pos: l.rpos, Rune: l.Peek(), Expected: expected})
+}
+
+// cause returns the cause of an ItemError or, if it has none, an error
+// holding its message.
+func (i Item) cause() error {
+	if i.Err.Cause != nil {
+		return i.Err.Cause
+	}
+	return errors.New(i.Value)
+}
@@ -0,0 +1,76 @@
+package lexrec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorCause(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Quote, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexer("TestErrorCause", strings.NewReader("\"ab\" 1\n\"cd\" x\nef 2\n\"gh 3\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := l.NextRecord(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = l.NextRecord()
+	var unexpected *UnexpectedRuneError
+	if !errors.As(err, &unexpected) {
+		t.Fatalf("expected an *UnexpectedRuneError, got %T: %v", err, err)
+	}
+	if unexpected.Rune != 'x' || unexpected.Pos != 12 || unexpected.Expected != "[0-9]" {
+		t.Errorf("expected 'x' at 12, got %q at %d (expected %s)", unexpected.Rune, unexpected.Pos, unexpected.Expected)
+	}
+
+	_, err = l.NextRecord()
+	if !errors.As(err, &unexpected) || unexpected.Rune != 'e' {
+		t.Errorf("expected an *UnexpectedRuneError for 'e', got %T: %v", err, err)
+	}
+
+	_, err = l.NextRecord()
+	var unterminated *UnterminatedQuoteError
+	if !errors.As(err, &unterminated) {
+		t.Fatalf("expected an *UnterminatedQuoteError, got %T: %v", err, err)
+	}
+	if unterminated.Pos != 19 {
+		t.Errorf("expected the quote at 19, got %d", unterminated.Pos)
+	}
+}
+
+func TestReadErrorCause(t *testing.T) {
+	failure := errors.New("disk on fire")
+	l, err := NewLexer("TestReadErrorCause", &errReader{"aaa", failure}, aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for item := range l.Items() {
+		if item.Type != ItemError {
+			continue
+		}
+		var read *ReadError
+		if !errors.As(item.Err.Cause, &read) || read.Name != "TestReadErrorCause" {
+			t.Errorf("expected a *ReadError, got %T: %v", item.Err.Cause, item.Err.Cause)
+		}
+		if !errors.Is(item.Err.Cause, failure) {
+			t.Errorf("expected the cause to wrap %v", failure)
+		}
+		var syntax *SyntaxError
+		if errors.As(item.Err.Cause, &syntax) {
+			t.Errorf("expected a read error not to be a *SyntaxError")
+		}
+		return
+	}
+	t.Error("expected an ItemError")
+}
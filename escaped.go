@@ -0,0 +1,123 @@
+package lexrec
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// InvalidEscapeError is the cause of an error reported by Escaped, or
+// by URI, for a malformed escape sequence: a malformed record.
+type InvalidEscapeError struct {
+	Pos    int64  // position, in bytes, of the backslash
+	Escape string // the escape sequence, as far as it was read
+}
+
+func (e *InvalidEscapeError) Error() string {
+	return fmt.Sprintf("invalid escape sequence %q", e.Escape)
+}
+
+// Escaped consumes a double-quoted string using the escape sequences
+// of C and Go: \a, \b, \f, \n, \r, \t, \v, \\, \', \", three octal
+// digits, \x followed by two hex digits, \u followed by four and \U
+// by eight, for logs that encode binary data in quoted fields.  Each
+// escape is validated: a \u or \U escape must name a valid Unicode
+// code point, and an octal escape a byte.  An invalid escape is
+// reported with an *InvalidEscapeError holding its exact position,
+// and an unescaped newline, or the end of the input, before the
+// closing quote with an *UnterminatedQuoteError.  The item's value is
+// the source of the string, quotes and escapes included.
+func Escaped(l *Lexer, t ItemType, emit bool) (success bool) {
+	r := l.Next()
+	if r != '"' {
+		l.Fail(&UnexpectedRuneError{Pos: l.rpos, Rune: r, Expected: `'"'`})
+		l.Backup()
+		return false
+	}
+	for {
+		switch l.Next() {
+		case '\\':
+			if !l.escape() {
+				return false
+			}
+		case '\n':
+			l.Fail(&UnterminatedQuoteError{Pos: l.tokenPos()})
+			l.Backup()
+			return false
+		case EOF:
+			l.Fail(&UnterminatedQuoteError{Pos: l.tokenPos()})
+			return false
+		case '"':
+			if emit {
+				l.Emit(t)
+			} else {
+				l.Skip()
+			}
+			return true
+		}
+	}
+}
+
+// escape consumes the remainder of an escape sequence whose backslash
+// has just been read, reporting an error if it is invalid.
+func (l *Lexer) escape() bool {
+	pos := l.rpos - 1
+	from := l.pos - 1
+	invalid := func() bool {
+		l.Fail(&InvalidEscapeError{Pos: pos, Escape: string(l.buf[from:l.pos])})
+		return false
+	}
+	r := l.Next()
+	var digits, base int
+	switch {
+	case strings.ContainsRune(`abfnrtv\'"`, r):
+		return true
+	case r >= '0' && r <= '7':
+		digits, base = 2, 8
+	case r == 'x':
+		digits, base = 2, 16
+	case r == 'u':
+		digits, base = 4, 16
+	case r == 'U':
+		digits, base = 8, 16
+	default:
+		if r == EOF || r == '\n' {
+			l.Backup()
+		}
+		return invalid()
+	}
+	v := rune(0)
+	if base == 8 {
+		v = r - '0'
+	}
+	for i := 0; i < digits; i++ {
+		d := digitValue(l.Next())
+		if d < 0 || d >= base {
+			l.Backup()
+			return invalid()
+		}
+		v = v*rune(base) + rune(d)
+	}
+	switch {
+	case base == 8 && v > 255:
+		return invalid()
+	case r == 'u' || r == 'U':
+		if !utf8.ValidRune(v) {
+			return invalid()
+		}
+	}
+	return true
+}
+
+// digitValue returns the value of the hex digit r, or -1.
+func digitValue(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= 'a' && r <= 'f':
+		return int(r-'a') + 10
+	case r >= 'A' && r <= 'F':
+		return int(r-'A') + 10
+	}
+	return -1
+}
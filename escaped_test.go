@@ -0,0 +1,57 @@
+package lexrec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEscaped(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Escaped, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	valid := []string{
+		`""`,
+		`"a\tb\\c\"d\'"`,
+		`"\x00\xfF\101\377"`,
+		`"é\U0001F600"`,
+		`"é"`,
+	}
+	for _, s := range valid {
+		items := lexAll(t, "TestEscaped", s+"\n", rec)
+		if len(items) != 3 || items[0].Type != ItemA || items[0].Value != s {
+			t.Errorf("%s: expected a single item, got %v", s, items)
+		}
+	}
+	invalid := []struct {
+		s      string
+		pos    int64
+		escape string
+	}{
+		{`"ab\q"`, 3, `\q`},
+		{`"\x4g"`, 1, `\x4`},
+		{`"é\400"`, 3, `\400`},
+		{`"\uD800"`, 1, `\uD800`},
+		{`"\U00110000"`, 1, `\U00110000`},
+		{`"\`, 1, `\`},
+	}
+	for _, test := range invalid {
+		items := lexAll(t, "TestEscaped", test.s+"\n", rec)
+		var ie *InvalidEscapeError
+		if items[0].Type != ItemError || !errors.As(items[0].Err.Cause, &ie) {
+			t.Errorf("%s: expected an *InvalidEscapeError, got %v", test.s, items[0])
+			continue
+		}
+		if ie.Pos != test.pos || ie.Escape != test.escape {
+			t.Errorf("%s: expected %q at %d, got %q at %d", test.s, test.escape, test.pos, ie.Escape, ie.Pos)
+		}
+	}
+	items := lexAll(t, "TestEscaped", `"abc`+"\n", rec)
+	var uqe *UnterminatedQuoteError
+	if !errors.As(items[0].Err.Cause, &uqe) {
+		t.Errorf("expected an *UnterminatedQuoteError, got %v", items[0])
+	}
+}
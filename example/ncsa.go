@@ -59,6 +59,13 @@ var acceptNewline = lexrec.Accept("\n", true)
 // accept a run of non-newline characters
 var acceptNotNewline = lexrec.ExceptRun("\n", true)
 
+// accept either a sequence of ASCII digits or the single char '-' followed
+// by a space
+var digitsOrMinus = lexrec.OneOf(lexrec.AcceptRun(digits, true), minusBeforeSpace)
+
+// accept an English month name or abbreviation
+var acceptMonth = lexrec.Month(lexrec.EnglishMonths, false)
+
 // ncsaRecord defines the NCSA Common Log Format
 var ncsaRecord = lexrec.Record{
 	Buflen:  8192,
@@ -73,7 +80,7 @@ var ncsaRecord = lexrec.Record{
 		{ItemIgnore, acceptOpenBrace, false},        // '['
 		{ItemRequestDay, lexrec.Digits, true},       // 2 digit day of month
 		{ItemIgnore, acceptSlash, false},            // '/'
-		{ItemRequestMonth, lexrec.Letters, true},    // 3-character month
+		{ItemRequestMonth, acceptMonth, true},       // 3-character month
 		{ItemIgnore, acceptSlash, false},            // '/'
 		{ItemRequestYear, lexrec.Digits, true},      // year
 		{ItemIgnore, acceptColon, false},            // ':'
@@ -103,19 +110,19 @@ var ncsaRecord = lexrec.Record{
 const sign = "+-"
 const digits = "0123456789"
 
-// digitsOrMinus consumes either a sequence of digits or the single
-// char '-' followed by a space.
-func digitsOrMinus(l *lexrec.Lexer, t lexrec.ItemType, emit bool) (success bool) {
-	if l.AcceptRun(digits) || (l.Accept("-") && l.Peek() == ' ') {
-		if emit {
-			l.Emit(t)
-		} else {
-			l.Skip()
-		}
-		return true
+// minusBeforeSpace consumes the single char '-' if it is followed by a
+// space.
+func minusBeforeSpace(l *lexrec.Lexer, t lexrec.ItemType, emit bool) (success bool) {
+	if !l.Accept("-") || l.Peek() != ' ' {
+		l.Errorf("expected a '-' followed by a space, got %q", l.Peek())
+		return false
 	}
-	l.Errorf("expected a '-' or a sequence of %q, got %q", digits, l.Peek())
-	return false
+	if emit {
+		l.Emit(t)
+	} else {
+		l.Skip()
+	}
+	return true
 }
 
 // numericTz consumes a timezone field in the format [+-]HHMM or [+-]HH:MM
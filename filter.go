@@ -0,0 +1,70 @@
+package lexrec
+
+import (
+	"bytes"
+)
+
+// Filter selects records by their raw bytes, before any of their
+// fields are lexed, so that the records nobody wants cost no more than
+// a search of their bytes.  A raw record runs up to the Record's
+// Terminator or, if it has none, a newline.  Set a Record's Filter to
+// a *Filter to apply it.  The count is updated by the Lexer's
+// goroutine, and is only safe to read once ItemEOF has been received.
+type Filter struct {
+	Include []string // if not empty, only records containing one of these are lexed
+	Exclude []string // records containing any of these are skipped
+	Skipped int64    // records skipped
+	include [][]byte
+	exclude [][]byte
+}
+
+// match reports whether the raw record is selected by the Filter.
+func (f *Filter) match(raw []byte) bool {
+	if f.include == nil && f.exclude == nil {
+		f.include = toBytes(f.Include)
+		f.exclude = toBytes(f.Exclude)
+	}
+	for _, b := range f.exclude {
+		if bytes.Contains(raw, b) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, b := range f.include {
+		if bytes.Contains(raw, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// toBytes returns strs as byte slices.
+func toBytes(strs []string) [][]byte {
+	b := make([][]byte, len(strs))
+	for i, s := range strs {
+		b[i] = []byte(s)
+	}
+	return b
+}
+
+// filter reports whether the record at the current position is
+// selected by the Record's Filter.  If it is not, the record and its
+// terminator are skipped.
+func (l *Lexer) filter() bool {
+	pos, rpos, width, eof := l.pos, l.rpos, l.width, l.eof
+	l.toTerminator()
+	if l.rec.Filter.match(l.buf[l.start:l.pos]) {
+		l.pos, l.rpos, l.width, l.eof = pos, rpos, width, eof
+		return true
+	}
+	l.rec.Filter.Skipped++
+	term := l.rec.Terminator
+	if term == nil {
+		term = newline
+	}
+	term(l)
+	l.Skip()
+	return false
+}
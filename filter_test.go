@@ -0,0 +1,44 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		filter *Filter
+		expect string
+	}{
+		{&Filter{Include: []string{"GET"}}, "ab GET,gh GET"},
+		{&Filter{Exclude: []string{"GET"}}, "cd PUT,ef"},
+		{&Filter{Include: []string{"GET", "PUT"}, Exclude: []string{"gh"}}, "ab GET,cd PUT"},
+	}
+	for _, test := range tests {
+		rec := Record{
+			Buflen:  8,
+			ErrorFn: SkipPast("\n"),
+			Filter:  test.filter,
+			States: []Binding{
+				{ItemA, Letters, true},
+				{ItemIgnore, Accept(" ", true), false},
+				{ItemB, Letters, true},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		items := lexAll(t, "TestFilter", "ab GET\ncd PUT\nef 1\ngh GET", rec)
+		got := ""
+		for _, item := range items {
+			switch item.Type {
+			case ItemA:
+				if got != "" {
+					got += ","
+				}
+				got += item.Value
+			case ItemB:
+				got += " " + item.Value
+			}
+		}
+		if got != test.expect {
+			t.Errorf("%v: expected %q, got %q", test.filter.Include, test.expect, got)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package lexrec
+
+import (
+	"strings"
+)
+
+// FixedWidth returns a StateFn that consumes exactly n bytes of the
+// input, whatever they hold, for the columns of fixed-width records
+// such as those of mainframe files.  If pad is not 0, leading and
+// trailing pad runes, e.g., spaces, are trimmed from the emitted
+// value.  An error is emitted if the input ends first.
+func FixedWidth(n int, pad rune) StateFn {
+	return fixed(n, pad, (*Lexer).nextByte, "bytes")
+}
+
+// FixedRunes is like FixedWidth, but consumes exactly n runes rather
+// than n bytes.
+func FixedRunes(n int, pad rune) StateFn {
+	return fixed(n, pad, func(l *Lexer) int { return int(l.Next()) }, "runes")
+}
+
+// fixed returns a StateFn that consumes n units of the input using
+// next, which returns EOF at the end of the input.
+func fixed(n int, pad rune, next func(l *Lexer) int, units string) StateFn {
+	cutset := string(pad)
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		for i := 0; i < n; i++ {
+			if next(l) == EOF {
+				l.Errorf("expected %d %s, got %d", n, units, i)
+				return false
+			}
+		}
+		switch {
+		case !emit:
+			l.Skip()
+		case pad != 0:
+			l.EmitValue(t, strings.Trim(string(l.Bytes()), cutset))
+		default:
+			l.Emit(t)
+		}
+		return true
+	}
+}
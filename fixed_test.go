@@ -0,0 +1,31 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestFixedWidth(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, FixedWidth(6, ' '), true},
+			{ItemB, FixedWidth(5, ' '), true},
+			{ItemAorB, FixedRunes(3, 0), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestFixedWidth", "ab      12 été\n  cd       a b\nshort\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemB, Pos: 6, Value: "12"}, {Type: ItemAorB, Pos: 11, Value: "été"}, {Type: ItemEOR, Pos: 17},
+		{Type: ItemA, Pos: 17, Value: "cd"}, {Type: ItemB, Pos: 23}, {Type: ItemAorB, Pos: 28, Value: "a b"}, {Type: ItemEOR, Pos: 32},
+		{Type: ItemA, Pos: 32, Value: "short\n"}, {Type: ItemError, Pos: 38},
+		{Type: ItemEOF, Pos: 38}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range items {
+		if items[i].Type != ItemError && items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package lexrec
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// LiteralFold returns a StateFn that consumes the non-empty sequence
+// of runes s, ignoring Unicode case, e.g., LiteralFold("GET") matches
+// "get" and "Get".  On a partial match nothing is consumed, and an
+// error is emitted.  The item is emitted as it appeared in the input.
+func LiteralFold(s string) StateFn {
+	expected := fmt.Sprintf("%q, ignoring case", s)
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		pos, rpos, width, eof := l.pos, l.rpos, l.width, l.eof
+		for _, want := range s {
+			if !foldEqual(l.Next(), want) {
+				l.pos, l.rpos, l.width, l.eof = pos, rpos, width, eof
+				l.unexpected(expected)
+				return false
+			}
+		}
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// AcceptFold consumes one rune from the valid set, ignoring Unicode
+// case, returning true on success.
+func (l *Lexer) AcceptFold(valid string) bool {
+	r := l.Next()
+	for _, v := range valid {
+		if foldEqual(r, v) {
+			return true
+		}
+	}
+	l.Backup()
+	return false
+}
+
+// AcceptFold returns a StateFn that consumes one rune from the valid
+// set, ignoring Unicode case.  If needed is true and no rune is
+// consumed, an error is emitted.
+func AcceptFold(valid string, needed bool) StateFn {
+	return funcState(func(l *Lexer) bool { return l.AcceptFold(valid) }, needed,
+		fmt.Sprintf("character from the set %q, ignoring case", valid))
+}
+
+// foldEqual reports whether the runes a and b are equal under simple
+// Unicode case folding.
+func foldEqual(a, b rune) bool {
+	if a == b {
+		return a != EOF
+	}
+	for f := unicode.SimpleFold(a); f != a; f = unicode.SimpleFold(f) {
+		if f == b {
+			return true
+		}
+	}
+	return false
+}
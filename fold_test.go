@@ -0,0 +1,29 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, LiteralFold("GET"), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, AcceptFold("xÉ", true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestFold", "GET X\nget é\nGeT x\ngeX x\nget y\nge\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: "GET"}, {Type: ItemB, Pos: 4, Value: "X"}, {Type: ItemEOR, Pos: 6},
+		{Type: ItemA, Pos: 6, Value: "get"}, {Type: ItemB, Pos: 10, Value: "é"}, {Type: ItemEOR, Pos: 13},
+		{Type: ItemA, Pos: 13, Value: "GeT"}, {Type: ItemB, Pos: 17, Value: "x"}, {Type: ItemEOR, Pos: 19},
+		{Type: ItemError, Pos: 19},
+		{Type: ItemA, Pos: 25, Value: "get"}, {Type: ItemError, Pos: 29},
+		{Type: ItemError, Pos: 31},
+		{Type: ItemEOF, Pos: 34}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+}
@@ -0,0 +1,103 @@
+package lexrec
+
+import (
+	"fmt"
+)
+
+// AcceptFunc consumes the next rune if fn returns true for it,
+// returning true on success.
+func (l *Lexer) AcceptFunc(fn func(r rune) bool) bool {
+	if r := l.Next(); r != EOF && fn(r) {
+		return true
+	}
+	l.Backup()
+	return false
+}
+
+// ExceptFunc consumes the next rune if fn returns false for it,
+// returning true on success.
+func (l *Lexer) ExceptFunc(fn func(r rune) bool) bool {
+	if r := l.Next(); r != EOF && !fn(r) {
+		return true
+	}
+	l.Backup()
+	return false
+}
+
+// AcceptRunFunc consumes a run of runes for which fn returns true,
+// returning true on success.
+func (l *Lexer) AcceptRunFunc(fn func(r rune) bool) bool {
+	for {
+		r := l.Next()
+		if r == EOF || !fn(r) {
+			break
+		}
+	}
+	l.Backup()
+	return l.pos > l.start
+}
+
+// ExceptRunFunc consumes a run of runes for which fn returns false,
+// returning true on success.
+func (l *Lexer) ExceptRunFunc(fn func(r rune) bool) bool {
+	for {
+		r := l.Next()
+		if r == EOF || fn(r) {
+			break
+		}
+	}
+	l.Backup()
+	return l.pos > l.start
+}
+
+// AcceptFunc returns a StateFn that consumes one rune for which fn
+// returns true, e.g., AcceptFunc(unicode.IsUpper, true).  If needed is
+// true and no rune is consumed, an error is emitted.
+func AcceptFunc(fn func(r rune) bool, needed bool) StateFn {
+	return funcState(func(l *Lexer) bool { return l.AcceptFunc(fn) }, needed,
+		fmt.Sprintf("a character accepted by %s", funcName(fn)))
+}
+
+// ExceptFunc returns a StateFn that consumes one rune for which fn
+// returns false.  If needed is true and no rune is consumed, an error
+// is emitted.
+func ExceptFunc(fn func(r rune) bool, needed bool) StateFn {
+	return funcState(func(l *Lexer) bool { return l.ExceptFunc(fn) }, needed,
+		fmt.Sprintf("a character rejected by %s", funcName(fn)))
+}
+
+// AcceptRunFunc returns a StateFn that consumes a run of runes for
+// which fn returns true.  If needed is true and no runes are consumed,
+// an error is emitted.
+func AcceptRunFunc(fn func(r rune) bool, needed bool) StateFn {
+	return funcState(func(l *Lexer) bool { return l.AcceptRunFunc(fn) }, needed,
+		fmt.Sprintf("a run of characters accepted by %s", funcName(fn)))
+}
+
+// ExceptRunFunc returns a StateFn that consumes a run of runes for
+// which fn returns false.  If needed is true and no runes are
+// consumed, an error is emitted.
+func ExceptRunFunc(fn func(r rune) bool, needed bool) StateFn {
+	return funcState(func(l *Lexer) bool { return l.ExceptRunFunc(fn) }, needed,
+		fmt.Sprintf("a run of characters rejected by %s", funcName(fn)))
+}
+
+// funcState returns a StateFn that consumes a token with accept,
+// emitting or skipping it, or if accept fails and needed is true,
+// reporting that expected was not found.
+func funcState(accept func(l *Lexer) bool, needed bool, expected string) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		if accept(l) {
+			if emit {
+				l.Emit(t)
+			} else {
+				l.Skip()
+			}
+			return true
+		}
+		if needed {
+			l.unexpected(expected)
+		}
+		return false
+	}
+}
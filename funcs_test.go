@@ -0,0 +1,33 @@
+package lexrec
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestFuncs(t *testing.T) {
+	rec := Record{
+		Buflen:  8,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, AcceptFunc(unicode.IsUpper, true), true},
+			{ItemB, AcceptRunFunc(unicode.IsLower, true), true},
+			{ItemIgnore, ExceptFunc(unicode.IsLetter, true), false},
+			{ItemAorB, ExceptRunFunc(unicode.IsSpace, true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestFuncs", "Éabc 12x\nab -\nZz 9\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: "É"}, {Type: ItemB, Pos: 2, Value: "abc"}, {Type: ItemAorB, Pos: 6, Value: "12x"}, {Type: ItemEOR, Pos: 10},
+		{Type: ItemError, Pos: 10},
+		{Type: ItemA, Pos: 15, Value: "Z"}, {Type: ItemB, Pos: 16, Value: "z"}, {Type: ItemAorB, Pos: 18, Value: "9"}, {Type: ItemEOR, Pos: 20},
+		{Type: ItemEOF, Pos: 20}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+	for _, item := range items {
+		if item.Type == ItemError && item.Value != "expected a character accepted by unicode.IsUpper, got 'a'" {
+			t.Errorf("unexpected error %q", item.Value)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package lexrec
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Discard reads items from l until ItemEOF, discarding them, and
+// returns the number of items, records and errors seen.  It is a
+// null consumer for benchmarking Records and StateFns.
+func Discard(l *Lexer) (items, records, errors int64) {
+	for {
+		item := l.NextItem()
+		switch item.Type {
+		case ItemEOF:
+			return
+		case ItemEOR:
+			records++
+		case ItemError:
+			errors++
+		}
+		items++
+	}
+}
+
+// GenFn is a function that appends a randomly generated field value
+// to b, using r as its source of randomness, and returns the extended
+// buffer.
+type GenFn func(r *rand.Rand, b []byte) []byte
+
+// Generate writes n records to w, each the concatenation of the values
+// produced by fields, in order.  The output is fully determined by
+// seed, so that benchmarks are reproducible.
+func Generate(w io.Writer, n int, seed int64, fields []GenFn) error {
+	r := rand.New(rand.NewSource(seed))
+	bw := bufio.NewWriter(w)
+	var b []byte
+	for i := 0; i < n; i++ {
+		b = b[:0]
+		for _, fn := range fields {
+			b = fn(r, b)
+		}
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// GenLiteral returns a GenFn that always produces s.
+func GenLiteral(s string) GenFn {
+	return func(r *rand.Rand, b []byte) []byte {
+		return append(b, s...)
+	}
+}
+
+// GenChoice returns a GenFn that produces one of choices, chosen
+// uniformly at random.
+func GenChoice(choices ...string) GenFn {
+	return func(r *rand.Rand, b []byte) []byte {
+		return append(b, choices[r.Intn(len(choices))]...)
+	}
+}
+
+// GenRun returns a GenFn that produces between min and max runes, inclusive,
+// each chosen uniformly at random from set.
+func GenRun(set string, min, max int) GenFn {
+	runes := []rune(set)
+	return func(r *rand.Rand, b []byte) []byte {
+		n := min + r.Intn(max-min+1)
+		for i := 0; i < n; i++ {
+			b = append(b, string(runes[r.Intn(len(runes))])...)
+		}
+		return b
+	}
+}
+
+// GenDigits returns a GenFn that produces between min and max ASCII
+// digits, inclusive.
+func GenDigits(min, max int) GenFn {
+	return GenRun("0123456789", min, max)
+}
+
+// GenLetters returns a GenFn that produces between min and max ASCII
+// letters, inclusive.
+func GenLetters(min, max int) GenFn {
+	return GenRun("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", min, max)
+}
+
+// GenerateRecords writes n records of rec to w, producing the value of
+// the i'th Binding with gens[i].  If rec has a Terminator, gens holds
+// one more GenFn, producing the terminator that follows the last
+// Binding.  Each generated record is lexed with rec before it is
+// written, and regenerated if it does not lex cleanly, so that the
+// output is valid sample data for the Record.  The output is fully
+// determined by seed.  GenerateRecords returns an error if gens does
+// not hold one GenFn per Binding, plus one for a Terminator, or if 100
+// attempts in a row fail to produce a valid record, which usually
+// means a GenFn does not match its Binding.
+func GenerateRecords(w io.Writer, rec Record, gens []GenFn, n int, seed int64) error {
+	want := len(rec.States)
+	if rec.Terminator != nil {
+		want++
+	}
+	if len(gens) != want {
+		return fmt.Errorf("expected %d generators, one per binding, got %d", want, len(gens))
+	}
+	r := rand.New(rand.NewSource(seed))
+	bw := bufio.NewWriter(w)
+	var b []byte
+	for i := 0; i < n; i++ {
+		state := 0
+		for attempt := 0; ; attempt++ {
+			if attempt == 100 {
+				if state == len(rec.States) {
+					return fmt.Errorf("no valid record after %d attempts, the record terminator fails to match", attempt)
+				}
+				return fmt.Errorf("no valid record after %d attempts, binding %d (%s) fails to match", attempt, state, funcName(rec.States[state].StateFn))
+			}
+			b = b[:0]
+			for _, fn := range gens {
+				b = fn(r, b)
+			}
+			if state = matchRecord(rec, b); state < 0 {
+				break
+			}
+		}
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// matchRecord lexes b as a single record of rec, returning -1 if every
+// Binding, and the Record's Terminator if it has one, matches and b is
+// consumed entirely, otherwise the index of the first Binding to fail,
+// or len(rec.States) if the Terminator fails.
+func matchRecord(rec Record, b []byte) int {
+	l := &Lexer{
+		r:     bytes.NewReader(b),
+		rec:   rec,
+		next:  make([]byte, len(b)+1),
+		trial: 1,
+	}
+	for i, s := range rec.States {
+		if !s.StateFn(l, s.ItemType, false) {
+			return i
+		}
+	}
+	if rec.Terminator != nil && !rec.Terminator(l) {
+		return len(rec.States)
+	}
+	if l.Peek() != EOF {
+		if rec.Terminator != nil {
+			return len(rec.States)
+		}
+		return len(rec.States) - 1
+	}
+	return -1
+}
@@ -0,0 +1,112 @@
+package lexrec
+
+import (
+	"bytes"
+	"testing"
+)
+
+// wordNumber generates records matching wordNumberRecord.
+var wordNumber = []GenFn{GenLetters(1, 8), GenLiteral(" "), GenDigits(1, 6), GenLiteral("\n")}
+
+var wordNumberRecord = Record{
+	Buflen:  4096,
+	ErrorFn: SkipPast("\n"),
+	States: []Binding{
+		{ItemA, Letters, true},
+		{ItemIgnore, Accept(" ", true), false},
+		{ItemB, Digits, true},
+		{ItemIgnore, Accept("\n", true), false}},
+}
+
+func TestGenerate(t *testing.T) {
+	a, b := new(bytes.Buffer), new(bytes.Buffer)
+	if err := Generate(a, 100, 1, wordNumber); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(b, 100, 1, wordNumber); err != nil {
+		t.Fatal(err)
+	}
+	if a.String() != b.String() {
+		t.Errorf("expected identical output for identical seeds")
+	}
+
+	l, err := NewLexer("TestGenerate", a, wordNumberRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, records, errors := Discard(l)
+	if items != 300 || records != 100 || errors != 0 {
+		t.Errorf("expected 300 items, 100 records and 0 errors, got %d, %d and %d", items, records, errors)
+	}
+}
+
+func BenchmarkLexer(b *testing.B) {
+	buf := new(bytes.Buffer)
+	if err := Generate(buf, 1000, 1, wordNumber); err != nil {
+		b.Fatal(err)
+	}
+	input := buf.Bytes()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l, err := NewLexer("BenchmarkLexer", bytes.NewReader(input), wordNumberRecord)
+		if err != nil {
+			b.Fatal(err)
+		}
+		Discard(l)
+	}
+}
+
+func TestGenerateRecords(t *testing.T) {
+	buf := new(bytes.Buffer)
+	// letters may produce an empty word, which GenerateRecords
+	// must discard.
+	gens := []GenFn{GenLetters(0, 3), GenLiteral(" "), GenDigits(1, 3), GenLiteral("\n")}
+	if err := GenerateRecords(buf, wordNumberRecord, gens, 50, 1); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLexer("TestGenerateRecords", buf, wordNumberRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, records, errors := Discard(l); records != 50 || errors != 0 {
+		t.Errorf("expected 50 records and 0 errors, got %d and %d", records, errors)
+	}
+
+	gens[2] = GenLetters(1, 3)
+	if err := GenerateRecords(buf, wordNumberRecord, gens, 1, 1); err == nil {
+		t.Errorf("expected an error for a generator that never matches")
+	}
+}
+
+func TestGenerateRecordsTerminator(t *testing.T) {
+	rec := Record{
+		Buflen:     16,
+		ErrorFn:    SkipRecord,
+		Terminator: TermString(";"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true}},
+	}
+	buf := new(bytes.Buffer)
+	gens := []GenFn{GenLetters(1, 3), GenLiteral(" "), GenDigits(1, 3), GenLiteral(";")}
+	if err := GenerateRecords(buf, rec, gens, 50, 1); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLexer("TestGenerateRecordsTerminator", buf, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, records, errors := Discard(l); records != 50 || errors != 0 {
+		t.Errorf("expected 50 records and 0 errors, got %d and %d", records, errors)
+	}
+
+	gens[3] = GenLiteral("\n")
+	if err := GenerateRecords(buf, rec, gens, 1, 1); err == nil {
+		t.Errorf("expected an error for a generator that never matches the terminator")
+	}
+	if err := GenerateRecords(buf, rec, gens[:3], 1, 1); err == nil {
+		t.Errorf("expected an error for a missing terminator generator")
+	}
+}
@@ -0,0 +1,87 @@
+package lexrec
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// LatLon returns a StateFn that consumes a latitude/longitude pair
+// of decimal degrees separated by sep, e.g., "37.7749,-122.4194",
+// and verifies that the latitude falls within [-90, 90] and the
+// longitude within [-180, 180].  The pair is emitted as a single
+// item normalized to "lat,lon".
+func LatLon(sep string) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		start, lat, lon, _, ok := scanLatLon(l, sep)
+		if !ok {
+			return false
+		}
+		if emit {
+			l.emit(Item{Type: t, Pos: start, Value: formatDegrees(lat) + "," + formatDegrees(lon)})
+		}
+		l.Skip()
+		return true
+	}
+}
+
+// LatLonSplit is like LatLon, but emits the latitude and longitude as
+// two separate items of type latType and lonType, each holding the
+// component as it appeared in the input.  The ItemType passed to the
+// StateFn is ignored.
+func LatLonSplit(sep string, latType, lonType ItemType) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		start, _, _, n, ok := scanLatLon(l, sep)
+		if !ok {
+			return false
+		}
+		if emit {
+			b := l.Bytes()
+			lonStart := n + len(sep)
+			l.emit(Item{Type: latType, Pos: start, Value: string(b[:n])})
+			l.emit(Item{Type: lonType, Pos: start + int64(lonStart), Value: string(b[lonStart:])})
+		}
+		l.Skip()
+		return true
+	}
+}
+
+// scanLatLon consumes a latitude/longitude pair separated by sep,
+// returning the starting position of the pair, the parsed values, and
+// the length in bytes of the latitude.  An error is emitted if the
+// pair is malformed or out of range.
+func scanLatLon(l *Lexer, sep string) (start int64, lat, lon float64, n int, ok bool) {
+	start, _ = l.Token()
+	if !scanDecimal(l) {
+		l.Errorf("bad latitude syntax: %q", l.Bytes())
+		return
+	}
+	n = l.Size()
+	lat, _ = strconv.ParseFloat(string(l.Bytes()), 64)
+	if lat < -90 || lat > 90 {
+		l.Errorf("latitude %q out of range [-90, 90]", l.Bytes())
+		return
+	}
+	for _, r := range sep {
+		if l.Next() != r {
+			l.Backup()
+			l.unexpected(fmt.Sprintf("separator %q after latitude", sep))
+			return
+		}
+	}
+	if !scanDecimal(l) {
+		l.Errorf("bad longitude syntax: %q", l.Bytes()[n+len(sep):])
+		return
+	}
+	lon, _ = strconv.ParseFloat(string(l.Bytes()[n+len(sep):]), 64)
+	if lon < -180 || lon > 180 {
+		l.Errorf("longitude %q out of range [-180, 180]", l.Bytes()[n+len(sep):])
+		return
+	}
+	ok = true
+	return
+}
+
+// formatDegrees formats a coordinate in the shortest decimal form.
+func formatDegrees(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,49 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestLatLon(t *testing.T) {
+	rec := Record{
+		Buflen:  32,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemEmit, LatLon(","), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+
+	items := lexAll(t, "TestLatLon", "37.77490,-122.4194\n", rec)
+	if items[0].Type != ItemEmit || items[0].Value != "37.7749,-122.4194" {
+		t.Errorf("expected normalized pair, got %q", items[0])
+	}
+
+	for _, input := range []string{"91,0\n", "0,181\n", "0;0\n", "a,0\n"} {
+		items = lexAll(t, "TestLatLon", input, rec)
+		if items[0].Type != ItemError {
+			t.Errorf("%q: expected ItemError, got %q", input, items[0])
+		}
+	}
+}
+
+func TestLatLonSplit(t *testing.T) {
+	rec := Record{
+		Buflen:  32,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemIgnore, LatLonSplit(", ", ItemA, ItemB), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+
+	items := lexAll(t, "TestLatLonSplit", "37.7749, -122.4194\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "37.7749"},
+		{Type: ItemB, Pos: 9, Value: "-122.4194"},
+		{Type: ItemEOR, Pos: 19},
+	}
+	for i, item := range expect {
+		if !sameItem(items[i], item) {
+			t.Errorf("expected %q, got %q", item, items[i])
+		}
+	}
+}
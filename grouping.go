@@ -0,0 +1,150 @@
+package lexrec
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Grouping describes how the digits of the integer part of a number
+// are grouped, e.g., "1,234,567.89", or "12.34.567,89" in Indian
+// grouping with European separators.
+type Grouping struct {
+	Sep      rune  // separator between groups, e.g., ','
+	Decimal  rune  // separator of the fraction, e.g., '.'; 0 means '.'
+	Sizes    []int // sizes of the groups from the right, the last repeating; nil means {3}
+	Optional bool  // if true, a number with no separators at all is also accepted
+}
+
+// GroupedInt returns a StateFn that consumes a base 10 integer with an
+// optional leading sign whose digits are grouped as described by g,
+// e.g., "-1,234,567", and verifies that it falls within the range
+// [min, max].  Misplaced separators, e.g., "1,23,4", are errors.  The
+// item is emitted as it appeared in the input.
+func GroupedInt(min, max int64, g Grouping) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		digits, ok := scanGrouped(l, g, false)
+		if !ok {
+			return false
+		}
+		n, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil || n < min || n > max {
+			l.Errorf("integer %q out of range [%d, %d]", l.Bytes(), min, max)
+			return false
+		}
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// GroupedFloat returns a StateFn that consumes a decimal number with
+// an optional leading sign and fraction, whose integer part is grouped
+// as described by g, e.g., "1,234.56", and verifies that it falls
+// within the range [min, max].  Misplaced separators, e.g.,
+// "1,23,4.56", are errors.  The item is emitted as it appeared in the
+// input.
+func GroupedFloat(min, max float64, g Grouping) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		digits, ok := scanGrouped(l, g, true)
+		if !ok {
+			return false
+		}
+		f, err := strconv.ParseFloat(digits, 64)
+		if err != nil || f < min || f > max {
+			l.Errorf("number %q out of range [%g, %g]", l.Bytes(), min, max)
+			return false
+		}
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// scanGrouped consumes a number grouped as described by g, with a
+// fraction if fraction is true, returning it without separators and
+// with a '.' for its decimal separator.  An error is reported if the
+// number is malformed.
+func scanGrouped(l *Lexer, g Grouping, fraction bool) (string, bool) {
+	decimal := g.Decimal
+	if decimal == 0 {
+		decimal = '.'
+	}
+	var sb strings.Builder
+	if l.Accept("+-") {
+		sb.Write(l.Bytes())
+	}
+	var groups []int
+	n := 0
+	for {
+		r := l.Next()
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+			n++
+			continue
+		}
+		if g.Sep != 0 && r == g.Sep {
+			groups = append(groups, n)
+			n = 0
+			continue
+		}
+		l.Backup()
+		break
+	}
+	groups = append(groups, n)
+	if n == 0 && len(groups) == 1 {
+		l.unexpected("number")
+		return "", false
+	}
+	if !g.valid(groups) {
+		l.Errorf("bad digit grouping: %q", l.Bytes())
+		return "", false
+	}
+	if fraction && l.Next() == decimal {
+		n := l.Size()
+		if !acceptDigits(l) {
+			l.Errorf("bad number syntax: %q", l.Bytes())
+			return "", false
+		}
+		sb.WriteByte('.')
+		sb.Write(l.Bytes()[n:])
+	} else if fraction {
+		l.Backup()
+	}
+	if l.isAlphaNumeric(l.Peek()) {
+		l.Next()
+		l.Errorf("bad number syntax: %q", l.Bytes())
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// valid reports whether groups, the number of digits in each group of
+// an integer part from the left, are grouped as described by g.
+func (g Grouping) valid(groups []int) bool {
+	sizes := g.Sizes
+	if len(sizes) == 0 {
+		sizes = []int{3}
+	}
+	if len(groups) == 1 {
+		return g.Optional || groups[0] <= sizes[0]
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		j := len(groups) - 1 - i
+		if j >= len(sizes) {
+			j = len(sizes) - 1
+		}
+		switch {
+		case i > 0 && groups[i] != sizes[j]:
+			return false
+		case i == 0 && (groups[i] < 1 || groups[i] > sizes[j]):
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestGrouping(t *testing.T) {
+	tests := []struct {
+		g      Grouping
+		input  string
+		expect bool
+	}{
+		{Grouping{Sep: ','}, "1,234,567.89", true},
+		{Grouping{Sep: ','}, "-12,345", true},
+		{Grouping{Sep: ','}, "999.5", true},
+		{Grouping{Sep: ','}, "1,23,4.56", false},
+		{Grouping{Sep: ','}, "1234,567", false},
+		{Grouping{Sep: ','}, ",234", false},
+		{Grouping{Sep: ','}, "1,234,", false},
+		{Grouping{Sep: ','}, "1234.5", false},
+		{Grouping{Sep: ',', Optional: true}, "1234.5", true},
+		{Grouping{Sep: ',', Optional: true}, "12,34.5", false},
+		{Grouping{Sep: ','}, "1,234.", false},
+		{Grouping{Sep: ',', Sizes: []int{3, 2}}, "12,34,567.8", true},
+		{Grouping{Sep: ',', Sizes: []int{3, 2}}, "1,234,567", false},
+		{Grouping{Sep: '.', Decimal: ','}, "1.234.567,89", true},
+		{Grouping{Sep: '.', Decimal: ','}, "1,234", true},
+		{Grouping{Sep: '.', Decimal: ','}, "1.23,4", false},
+	}
+	for _, test := range tests {
+		rec := Record{
+			Buflen:  4,
+			ErrorFn: SkipPast("\n"),
+			States: []Binding{
+				{ItemA, GroupedFloat(-1e9, 1e9, test.g), true},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		items := lexAll(t, "TestGrouping", test.input+"\n", rec)
+		if ok := items[0].Type == ItemA && items[0].Value == test.input; ok != test.expect {
+			t.Errorf("%+v %q: expected %v, got %v", test.g, test.input, test.expect, items)
+		}
+	}
+}
+
+func TestGroupedInt(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, GroupedInt(0, 1000000, Grouping{Sep: ','}), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestGroupedInt", "1,000\n1,000,001\n12,34\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: "1,000"}, {Type: ItemEOR, Pos: 6},
+		{Type: ItemError, Pos: 6},
+		{Type: ItemError, Pos: 16},
+		{Type: ItemEOF, Pos: 22}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+}
@@ -0,0 +1,126 @@
+package lexrec
+
+import (
+	"errors"
+	"io"
+)
+
+// errUnread is returned by UnreadByte and UnreadRune if nothing was
+// read, or the last read has already been stepped back over.
+var errUnread = errors.New("lexrec: invalid use of UnreadByte or UnreadRune")
+
+// Input is a view of the input of a Lexer from its current position,
+// for handing a field to a parser that reads from an io.Reader,
+// io.ByteScanner or io.RuneScanner, such as encoding/json, without
+// first extracting and copying the token.  Whatever is read through
+// the Input is consumed by the Lexer, as if by Next, and becomes part
+// of the current token.  An Input is only valid within the StateFn
+// that obtained it.
+type Input struct {
+	l    *Lexer
+	last int // width of the last byte or rune read, or 0 if it cannot be unread
+}
+
+// Input returns a view of the input of l from its current position.
+// A parser that reads ahead of what it uses, such as a json.Decoder,
+// consumes too much; the excess may be returned with Unread, e.g.:
+//
+//	func jsonField(l *lexrec.Lexer, t lexrec.ItemType, emit bool) bool {
+//		in := l.Input()
+//		dec := json.NewDecoder(in)
+//		var v any
+//		if err := dec.Decode(&v); err != nil {
+//			l.Errorf("invalid JSON: %v", err)
+//			return false
+//		}
+//		in.Unread(l.Size() - int(dec.InputOffset()))
+//		...
+//	}
+func (l *Lexer) Input() *Input {
+	return &Input{l: l}
+}
+
+// Read reads up to len(p) bytes of the input into p.  At the end of
+// the input it returns io.EOF, or the error of the Lexer's reader if
+// it failed.
+func (in *Input) Read(p []byte) (int, error) {
+	l := in.l
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if l.Peek() == EOF {
+		return 0, in.err()
+	}
+	n := copy(p, l.buf[l.pos:])
+	l.pos += n
+	l.rpos += int64(n)
+	l.width, in.last = 0, 0
+	return n, nil
+}
+
+// ReadByte reads the next byte of the input.
+func (in *Input) ReadByte() (byte, error) {
+	in.last = 0
+	b := in.l.nextByte()
+	if b == EOF {
+		return 0, in.err()
+	}
+	in.last = 1
+	return byte(b), nil
+}
+
+// UnreadByte steps back over the byte last read by ReadByte.
+func (in *Input) UnreadByte() error {
+	return in.unread()
+}
+
+// ReadRune reads the next UTF-8 encoded rune of the input.
+func (in *Input) ReadRune() (r rune, size int, err error) {
+	in.last = 0
+	r = in.l.Next()
+	if r == EOF {
+		return 0, 0, in.err()
+	}
+	in.last = in.l.width
+	return r, in.last, nil
+}
+
+// UnreadRune steps back over the rune last read by ReadRune.
+func (in *Input) UnreadRune() error {
+	return in.unread()
+}
+
+// Unread returns the last n bytes read to the input, e.g., those a
+// parser read ahead but did not use.  No more than the current token
+// may be returned.
+func (in *Input) Unread(n int) {
+	l := in.l
+	if n > l.pos-l.start {
+		n = l.pos - l.start
+	}
+	if n <= 0 {
+		return
+	}
+	l.pos -= n
+	l.rpos -= int64(n)
+	l.width, in.last = 0, 0
+	l.eof = false
+}
+
+// unread steps back over the last byte or rune read, which may only be
+// done once per read.
+func (in *Input) unread() error {
+	if in.last == 0 {
+		return errUnread
+	}
+	in.Unread(in.last)
+	return nil
+}
+
+// err returns the error for the end of the input.
+func (in *Input) err() error {
+	if in.l.err != nil {
+		return in.l.err
+	}
+	return io.EOF
+}
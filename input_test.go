@@ -0,0 +1,85 @@
+package lexrec
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"unicode"
+)
+
+// jsonValue is a StateFn that consumes a JSON value using
+// encoding/json, returning what it read ahead to the input.
+func jsonValue(l *Lexer, t ItemType, emit bool) bool {
+	in := l.Input()
+	dec := json.NewDecoder(in)
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		l.Errorf("invalid JSON: %v", err)
+		return false
+	}
+	in.Unread(l.Size() - int(dec.InputOffset()))
+	if emit {
+		l.Emit(t)
+	} else {
+		l.Skip()
+	}
+	return true
+}
+
+func TestInputJSON(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, jsonValue, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestInputJSON", "{\"a\": [1, \"x y\"]} 12\n{\"b\": } 3\n\"ok\" 4\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: `{"a": [1, "x y"]}`}, {Type: ItemB, Pos: 18, Value: "12"}, {Type: ItemEOR, Pos: 21},
+		{Type: ItemError, Pos: 30},
+		{Type: ItemA, Pos: 32, Value: `"ok"`}, {Type: ItemB, Pos: 37, Value: "4"}, {Type: ItemEOR, Pos: 39},
+		{Type: ItemEOF, Pos: 39}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+}
+
+func TestInputRuneScanner(t *testing.T) {
+	var got []string
+	word := func(l *Lexer, it ItemType, emit bool) bool {
+		var in io.RuneScanner = l.Input()
+		if err := in.UnreadRune(); err == nil {
+			t.Errorf("expected UnreadRune to fail before ReadRune")
+		}
+		for {
+			r, size, err := in.ReadRune()
+			if err != nil {
+				break
+			}
+			if r == 'é' && size != 2 {
+				t.Errorf("expected %q to be 2 bytes, got %d", r, size)
+			}
+			if !unicode.IsLetter(r) {
+				in.UnreadRune()
+				break
+			}
+		}
+		got = append(got, string(l.Bytes()))
+		l.Emit(it)
+		return true
+	}
+	rec := Record{
+		Buflen:  2,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, word, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	lexAll(t, "TestInputRuneScanner", "café\nab\n", rec)
+	if len(got) != 2 || got[0] != "café" || got[1] != "ab" {
+		t.Errorf("expected [café ab], got %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package lexrec
+
+import (
+	"strconv"
+)
+
+// SignedInteger returns a StateFn that consumes a base 10 integer with
+// an optional leading '+' or '-' and, if minDigits or maxDigits is
+// > 0, at least minDigits and at most maxDigits digits, not counting
+// the sign.  The integer must fit in an int64.  The item is emitted as
+// it appeared in the input.
+func SignedInteger(minDigits, maxDigits int) StateFn {
+	return integerFn(minDigits, maxDigits, true)
+}
+
+// UnsignedInteger is like SignedInteger but consumes an integer with
+// no sign, which must fit in a uint64.
+func UnsignedInteger(minDigits, maxDigits int) StateFn {
+	return integerFn(minDigits, maxDigits, false)
+}
+
+func integerFn(minDigits, maxDigits int, signed bool) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		if signed {
+			l.Accept("+-")
+		}
+		n := l.Size()
+		if !acceptDigits(l) {
+			l.unexpected("integer")
+			return false
+		}
+		if l.isAlphaNumeric(l.Peek()) {
+			l.Next()
+			l.Errorf("bad integer syntax: %q", l.Bytes())
+			return false
+		}
+		switch digits := l.Size() - n; {
+		case minDigits > 0 && digits < minDigits:
+			l.Errorf("integer %q has fewer than %d digits", l.Bytes(), minDigits)
+			return false
+		case maxDigits > 0 && digits > maxDigits:
+			l.Errorf("integer %q has more than %d digits", l.Bytes(), maxDigits)
+			return false
+		}
+		kind := "uint64"
+		_, err := strconv.ParseUint(string(l.Bytes()), 10, 64)
+		if signed {
+			kind = "int64"
+			_, err = strconv.ParseInt(string(l.Bytes()), 10, 64)
+		}
+		if err != nil {
+			l.Errorf("integer %q overflows %s", l.Bytes(), kind)
+			return false
+		}
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
@@ -0,0 +1,44 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestSignedInteger(t *testing.T) {
+	tests := []struct {
+		fn    StateFn
+		input string
+		typ   ItemType
+		value string
+	}{
+		{SignedInteger(0, 0), "-9223372036854775808\n", ItemEmit, "-9223372036854775808"},
+		{SignedInteger(0, 0), "9223372036854775808\n", ItemError, ""},
+		{SignedInteger(0, 0), "+007\n", ItemEmit, "+007"},
+		{SignedInteger(2, 3), "-12\n", ItemEmit, "-12"},
+		{SignedInteger(2, 3), "-1\n", ItemError, ""},
+		{SignedInteger(2, 3), "1234\n", ItemError, ""},
+		{SignedInteger(0, 0), "-\n", ItemError, ""},
+		{SignedInteger(0, 0), "12a\n", ItemError, ""},
+		{UnsignedInteger(0, 0), "18446744073709551615\n", ItemEmit, "18446744073709551615"},
+		{UnsignedInteger(0, 0), "18446744073709551616\n", ItemError, ""},
+		{UnsignedInteger(0, 0), "-1\n", ItemError, ""},
+		{UnsignedInteger(0, 0), "+1\n", ItemError, ""},
+		{UnsignedInteger(4, 0), "0042\n", ItemEmit, "0042"},
+		{UnsignedInteger(4, 0), "042\n", ItemError, ""},
+	}
+	for _, test := range tests {
+		rec := Record{
+			Buflen:  16,
+			ErrorFn: SkipPast("\n"),
+			States: []Binding{
+				{ItemEmit, test.fn, true},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		items := lexAll(t, "TestSignedInteger", test.input, rec)
+		if items[0].Type != test.typ {
+			t.Errorf("%q: expected type %d, got %q", test.input, test.typ, items[0])
+		} else if test.typ == ItemEmit && items[0].Value != test.value {
+			t.Errorf("%q: expected %q, got %q", test.input, test.value, items[0].Value)
+		}
+	}
+}
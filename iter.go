@@ -0,0 +1,40 @@
+package lexrec
+
+import (
+	"iter"
+)
+
+// Items returns an iterator over the items lexed from the input, e.g.:
+//
+//	for item, err := range l.Items() {
+//		if err != nil {
+//			...
+//		}
+//	}
+//
+// Each ItemError is paired with its Err.Cause, or if it has none an
+// error holding its message; the error is nil for every other item.
+// The iteration ends after the ItemEOF, or after the ItemError
+// reporting that the Lexer was closed or its context canceled.
+// Breaking out of the loop early closes the Lexer.
+func (l *Lexer) Items() iter.Seq2[Item, error] {
+	return func(yield func(Item, error) bool) {
+		for {
+			item, ok := l.nextItem()
+			if !ok && !l.canceled() {
+				return
+			}
+			var err error
+			if item.Type == ItemError {
+				err = item.cause()
+			}
+			if !yield(item, err) {
+				l.Close()
+				return
+			}
+			if !ok || item.Type == ItemEOF {
+				return
+			}
+		}
+	}
+}
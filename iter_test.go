@@ -0,0 +1,53 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLexerItems(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	input := "ab\nc1\nde\n"
+	expect := lexAll(t, "TestLexerItems", input, rec)
+
+	l, err := NewLexer("TestLexerItems", strings.NewReader(input), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []Item
+	errs := 0
+	for item, err := range l.Items() {
+		items = append(items, item)
+		if (err != nil) != (item.Type == ItemError) {
+			t.Errorf("expected an error only for an ItemError, got %v with %v", item, err)
+		}
+		if err != nil {
+			errs++
+		}
+	}
+	if summarize(items) != summarize(expect) {
+		t.Errorf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	if errs != 1 {
+		t.Errorf("expected 1 error, got %d", errs)
+	}
+}
+
+func TestLexerItemsBreak(t *testing.T) {
+	l, err := NewLexer("TestLexerItemsBreak", strings.NewReader(strings.Repeat("a\n", 100000)), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range l.Items() {
+		break
+	}
+	if !finished(l) {
+		t.Errorf("expected items channel to be closed after breaking out of Items")
+	}
+}
@@ -18,6 +18,104 @@ The caller defines a Record that consists of
    typically this would be accomplished by skipping the remainder of
    the record.
 
+ - Classifier, an optional set of rune classes used by the Digits,
+   Letters, Spaces and Number StateFns.  If nil, Unicode categories
+   are used.
+
+ - Mask, an optional MaskFn applied to the value of every emitted
+   item, e.g., MaskPAN to hide payment card numbers.  It is also
+   applied to the messages of errors, including their Context
+   excerpts, and to the Msg of a SyntaxError cause, but not to the
+   bytes written to Quarantine.
+
+ - OnSpan, an optional SpanFn called with the offset and length of
+   each record, e.g., a Manifest's Span method.
+
+ - OnStart, an optional StartFn called with the number and offset of
+   each record before it is lexed, so that with OnSpan a consumer can
+   bracket each record, e.g., to time it or to batch its items.
+
+ - Salvage, if true, a record interrupted by the end of the input
+   ends with an ItemTruncated holding the unparsed remainder rather
+   than with an ItemError.
+
+ - AutoBuflen, if greater than zero, the number of records after
+   which Buflen is replaced by a size computed from their mean length.
+
+ - Context, if greater than zero, the number of bytes of input
+   surrounding an error to quote, with a caret, in its message.
+
+ - Recovered, if true, each recovery by ErrorFn is followed by an
+   ItemRecovered giving the number of bytes of the record lost.
+
+ - Quarantine, if set, receives the exact bytes of each record
+   discarded by ErrorFn, so that malformed input can be kept aside.
+   The bytes are written as they were read, without applying Mask.
+
+ - Cooldown, if set, switches to a cheap scan for the next clean
+   record after a run of consecutive malformed records.
+
+ - Terminator, if set, defines the end of a record.  It is consumed
+   after the last of the States, and is used by SkipRecord, Sync and
+   Cooldown to find the boundaries between records.
+
+ - Sketches, an optional SketchMap of streaming summaries, such as
+   TopK and HyperLogLog, updated with the values of emitted items.
+
+ - LineStats, if set, collects statistics on the length of records,
+   and may emit an ItemAnomaly after records of unusual length.
+
+ - Torn, if set, the signature of the start of a record, such as
+   rec.Signature(3).  When a record fails, the rest of its line is
+   searched for the signature, and if it is found the record is taken
+   to have been torn by a concurrent write: the fragment is emitted
+   as an ItemTorn, which begins the interrupting record, and lexing
+   resumes at the signature.
+
+ - Filter, if set, selects records by searching their raw bytes, so
+   that records that are not wanted are skipped without being lexed.
+
+ - Arena, if set, allocates the values of emitted items, e.g., from
+   the large chunks of a ByteArena, rather than with an allocation
+   each.
+
+ - ZeroCopy, if true, each item reported by Emit also carries its
+   value in Bytes, a view of the Lexer's read buffer rather than a
+   copy.  Bytes is only valid until the next record is lexed, and must
+   be copied, e.g., with Item.Clone, to be kept any longer.  Value
+   remains a copy.  Bytes is nil if the value was rewritten, e.g., by
+   Mask.  ZeroCopy requires a Lexer from NewLexerSync.
+
+ - Only, if not empty, the item types to emit.  Items of other types
+   are skipped as if their Binding's emit were false, so that a Record
+   shared by several consumers need only deliver the fields each one
+   wants.  ItemEOR, ItemEOF, ItemError and the other built-in types are
+   always emitted.
+
+ - Whitespace, if set, the policy for the whitespace separating fields
+   consumed by Sep: whether a run of separators or exactly one, and
+   whether tabs are allowed, with violations reported as errors or, as
+   an ItemWarning, accepted.
+
+ - Empty, what to do with empty records, e.g., blank lines, which hold
+   nothing but their terminator: lex them as usual, skip them, emit
+   an ItemEOR for each, or report each as an error.  Zero-byte input,
+   whatever the Empty action, yields nothing but an ItemEOF.
+
+ - ReadSizes, if set, the sizes of successive reads from the input,
+   used in turn and repeated, for testing how tokens that straddle
+   read boundaries are lexed.  A ChunkReader or SplitReader does the
+   same for any reader.
+
+ - PosMap, if set, translates the positions reported on items and
+   their errors from the Lexer's input into an original source, for
+   input read through a transforming reader, e.g., one decompressing
+   or transcoding it.
+
+ - Cache, if set, remembers the items of recently lexed records by
+   their raw bytes, so that a record repeated verbatim, e.g., a
+   heartbeat line, is re-emitted without running its StateFns.
+
 The Lexer will iterate over States, calling each StateFn in turn. On
 success the StateFn will emit the ItemType or not, depending on the
 value of the emit boolean.
@@ -28,6 +126,17 @@ events that interest them.
 Once the end of States is reached, an ItemEOR will be emitted.  Once
 the end of the input has been reached an ItemEOF will be emitted.
 
+Each ItemError carries the error reported in its Err.Cause, which can
+be inspected with errors.As to tell, e.g., a *ReadError from the
+input apart from a *SyntaxError or *UnexpectedRuneError in a record.
+
+Built with the lexrec_sync tag, or for a wasm target, the package
+starts no goroutines and uses no channels: NewLexer, NewLexerContext
+and a CompiledRecord's lexers all run in the caller's goroutine, as
+one from NewLexerSync does, and NewLexerRun lexes the whole input
+before returning.  Stream, which delivers its values on a channel,
+still needs a goroutine.  Synchronous reports which build is in use.
+
 Much of this library was inspired by and derived from by Rob Pike's
 template parsing libary (http://golang.org/pkg/text/template/parse/).
 Any elegant bits in this library are from his original library.
@@ -35,10 +144,11 @@ Any elegant bits in this library are from his original library.
 package lexrec
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"strings"
-	"unicode"
 	"unicode/utf8"
 )
 
@@ -68,11 +178,31 @@ const (
 	ItemEOF                   // end of file
 )
 
+// The following ItemTypes are generated by the Lexer itself.  They
+// are negative so as not to collide with the ItemTypes defined by
+// callers, which by convention begin at ItemEOF + 1.
+const (
+	ItemTruncated ItemType = -1 - iota // record interrupted by the end of the input
+	ItemRecovered                      // input skipped by ErrorFn: Pos is where lexing resumed, Value the number of bytes skipped
+	ItemAnomaly                        // record of anomalous length, flagged after its end: Pos is where it started
+	ItemTorn                           // start of a record that interrupted another: Value is the fragment it interrupted
+	ItemWarning                        // input accepted despite violating a Record's policy: Value describes the violation
+)
+
 // Item represents a lexed token item
 type Item struct {
 	Type  ItemType // the type of this item
 	Pos   int64    // the starting position, in bytes, of this item
 	Value string   //  the value of this item
+	Err   Error    // for an ItemError, where the error occurred
+	Bytes []byte   // for a ZeroCopy Record, the value as a view of the read buffer
+}
+
+// Error describes where in a Record an ItemError occurred, and why.
+type Error struct {
+	State   int    // index of the Binding whose StateFn failed, or -1 if no Binding was running
+	Binding string // name of the Binding's item type, or of its StateFn if the type is unnamed
+	Cause   error  // the error reported, e.g., a *SyntaxError or *ReadError
 }
 
 // Binding maps a lexer ItemType to a lexer StateFn. The boolean emit
@@ -83,11 +213,39 @@ type Binding struct {
 	Emit     bool     // emit the item type or skip over it
 }
 
+// NameMap maps an ItemType to a name for the field it represents.
+type NameMap map[ItemType]string
+
 // Record represents a log record
 type Record struct {
-	Buflen  int       // size of initial buffer, this will be grown as necessary
-	States  []Binding // lexer states that make up a record
-	ErrorFn ErrorFn   // error function to apply if the lexer encounters a malformed record
+	Buflen     int         // size of initial buffer, this will be grown as necessary
+	States     []Binding   // lexer states that make up a record
+	ErrorFn    ErrorFn     // error function to apply if the lexer encounters a malformed record
+	Classifier *Classifier // rune classes for Digits, Letters, Spaces and Number; nil means UnicodeClassifier
+	Mask       MaskFn      // applied to the value of every emitted item and to error messages; nil leaves them unchanged
+	OnSpan     SpanFn      // called with the extent of each record once it has been lexed; may be nil
+	OnStart    StartFn     // called with the number and position of each record before it is lexed; may be nil
+	Names      NameMap     // names of the record's item types, used to refer to fields by name
+	Salvage    bool        // emit ItemTruncated rather than an error when the input ends mid-record
+	Coverage   *Coverage   // if set, counts the outcomes of each Binding
+	AutoBuflen int         // if > 0, resize the read buffer from the mean size of this many records
+	Context    int         // if > 0, errors include their field offset and up to this many bytes of context
+	Recovered  bool        // emit an ItemRecovered after ErrorFn recovers from a malformed record
+	Quarantine io.Writer   // if set, receives the bytes of each record skipped by ErrorFn
+	Cooldown   *Cooldown   // if set, how to skip past a run of consecutive malformed records
+	Terminator Terminator  // if set, consumed after the last binding to end each record
+	Sketches   SketchMap   // summaries updated with the value of each emitted item of their type
+	LineStats  *LineStats  // if set, collects record length statistics and flags anomalous lengths
+	Torn       []Binding   // if set, the start of a record, looked for mid-line to split torn records
+	Filter     *Filter     // if set, selects the records to lex by their raw bytes
+	Arena      Arena       // if set, allocates the values of items reported by Emit
+	ZeroCopy   bool        // items reported by Emit carry a view of the read buffer in Bytes; requires NewLexerSync
+	Only       []ItemType  // if not empty, the only item types emitted, besides the built-in types
+	Whitespace *Whitespace // if set, the policy applied by Sep to the whitespace between fields
+	Empty      EmptyAction // what to do with an empty record, one holding only its terminator
+	ReadSizes  []int       // if set, the sizes of successive reads from the input, for testing
+	PosMap     PosMapper   // if set, translates the positions of items and errors to an original source
+	Cache      *Cache      // if set, re-emits the items of records repeated verbatim without lexing them
 }
 
 func NewRecord(n int, states []Binding, errorFn ErrorFn) Record {
@@ -112,11 +270,52 @@ type Lexer struct {
 	start   int       // start position of item in buf
 	width   int       // width of most recent rune read from buf
 	lastPos int64     // position of most recent item returned by nextItem
+	nrec    int64     // number of records lexed so far
+	encrypt EncryptFn // encryption applied to emitted values by an Encrypt StateFn
+	encErr  bool      // true if encrypt failed during the current StateFn
+	capture captureFn // if set, receives emitted items in place of the client
+	trial   int       // > 0 while trying StateFns without consuming input
+	hold    bool      // true if errors are being held in held
+	held    []Item    // errors emitted by the current StateFn while hold is true
+	gate    gate      // blocks reading from r while the Lexer is paused
+	out     captureFn // if set, receives items in place of the items channel
+	keep    bool      // true while the buffer must not be shifted by Skip
+	scratch *Scratch  // per-Lexer temporary storage for StateFns
+	running int       // 1 + the index of the running Binding, or 0 if none is running
+	recPos  int64     // position of the start of the current record
+	fails   int       // number of consecutive records that have failed
+	queue   []Item    // items lexed by Scan
+	head    int       // index in queue of the next item to be returned by Scan
+	item    Item      // the item most recently returned by Scan
+	ended   bool      // true once Scan has lexed the end of the input
+	fields  Buffers   // if set, receives the values of emitted items in place of the client
+	err     error     // first error, other than io.EOF, returned by r
+	reads   int       // number of reads from r, used to pick from rec.ReadSizes
+	checker *checker  // if set, notes calls to Next and Backup for CheckStateFn
+	batch   *batch    // if set, records the items sent for the Record's Cache
+	// if set, sanitizer is applied to emitted values, and rejected
+	// is set if it rejects one during the current StateFn
+	sanitizer *Sanitizer
+	rejected  bool
+	// if set, canceling ctx, as stop does, stops the Lexer; ctx is
+	// derived from parent
+	ctx    context.Context
+	stop   context.CancelFunc
+	parent context.Context
 }
 
 // NewLexer returns a lexer for rec records from the UTF-8 reader r.
 // The name is only used for debugging messages.
 func NewLexer(name string, r io.Reader, rec Record) (l *Lexer, err error) {
+	return NewLexerContext(context.Background(), name, r, rec)
+}
+
+// NewLexerContext returns a lexer for rec records from the UTF-8
+// reader r that stops when ctx is canceled, even if the client has
+// stopped calling NextItem.  Once a canceled Lexer has stopped,
+// NextItem returns an ItemError reporting the cancellation rather than
+// an ItemEOF.  The name is only used for debugging messages.
+func NewLexerContext(ctx context.Context, name string, r io.Reader, rec Record) (l *Lexer, err error) {
 	if len(rec.States) == 0 {
 		err = fmt.Errorf("rec.states must not be empty.")
 		return
@@ -129,15 +328,20 @@ func NewLexer(name string, r io.Reader, rec Record) (l *Lexer, err error) {
 		err = fmt.Errorf("rec.ErrorFn must not be nil")
 		return
 	}
+	if rec.ZeroCopy {
+		err = fmt.Errorf("rec.ZeroCopy requires NewLexerSync")
+		return
+	}
 	l = &Lexer{
-		name:  name,
-		r:     r,
-		rec:   rec,
-		items: make(chan Item),
-		next:  make([]byte, rec.Buflen),
-		eof:   false,
-	}
-	go l.run()
+		name: name,
+		r:    r,
+		rec:  rec,
+		next: make([]byte, rec.Buflen),
+		eof:  false,
+	}
+	l.parent = ctx
+	l.ctx, l.stop = context.WithCancel(ctx)
+	l.spawn()
 	return
 }
 
@@ -153,18 +357,19 @@ func NewLexerRun(name string, r io.Reader, rec Record, runFn RunFn) (l *Lexer, e
 		err = fmt.Errorf("rec.ErrorFn must not be nil")
 		return
 	}
+	if rec.ZeroCopy {
+		err = fmt.Errorf("rec.ZeroCopy requires NewLexerSync")
+		return
+	}
 	l = &Lexer{
-		name:  name,
-		r:     r,
-		rec:   rec,
-		items: make(chan Item),
-		next:  make([]byte, rec.Buflen),
-		eof:   false,
-	}
-	go func(l *Lexer, runFn RunFn) {
-		defer close(l.items)
-		runFn(l)
-	}(l, runFn)
+		name: name,
+		r:    r,
+		rec:  rec,
+		next: make([]byte, rec.Buflen),
+		eof:  false,
+	}
+	l.ctx, l.stop = context.WithCancel(context.Background())
+	l.spawnRun(runFn)
 
 	return
 }
@@ -172,49 +377,202 @@ func NewLexerRun(name string, r io.Reader, rec Record, runFn RunFn) (l *Lexer, e
 // run consumes input, emitting ItemType events until EOF is reached.
 func (l *Lexer) run() {
 	defer close(l.items)
-	eor := len(l.rec.States) - 1
-	for {
-		for i, state := range l.rec.States {
-			if !state.StateFn(l, state.ItemType, state.Emit) {
-				l.rec.ErrorFn(l)
-				break
-			}
-			if i == eor || l.eof {
-				l.Emit(ItemEOR)
-			}
+	for l.record() {
+	}
+}
+
+// record lexes the next record of the input, returning false once the
+// end of the input has been reached and ItemEOF emitted.
+func (l *Lexer) record() bool {
+	if l.rpos == 0 && l.Peek() == EOF {
+		// zero-byte input holds no records at all.
+		l.Emit(ItemEOF)
+		return false
+	}
+	if l.rec.Quarantine != nil || l.rec.Torn != nil || l.rec.ZeroCopy {
+		// hold each record in the buffer until it has been
+		// lexed, in case it must be quarantined or split, or
+		// its items share the buffer.
+		l.keep = false
+		l.Skip()
+		l.keep = true
+	}
+	if l.rec.Filter != nil && !l.filter() {
+		if l.Peek() == EOF {
+			l.Emit(ItemEOF)
+			return false
 		}
+		return true
+	}
+	if l.rec.Empty != EmptyLex && l.empty() {
 		if l.Peek() == EOF {
 			l.Emit(ItemEOF)
-			break
+			return false
 		}
+		return true
+	}
+	start := l.tokenPos()
+	l.recPos = start
+	if l.rec.OnStart != nil && l.Peek() != EOF {
+		l.rec.OnStart(l.nrec+1, start)
 	}
+	var failed bool
+	if l.rec.Cache != nil && l.fields == nil {
+		failed = l.cached(start)
+	} else {
+		failed = l.bindings()
+	}
+	if !failed {
+		l.fails = 0
+	}
+	l.span(start, failed)
+	if l.rec.LineStats != nil {
+		l.lineStats(start)
+	}
+	l.autoBuflen()
+	if l.Peek() == EOF {
+		l.Emit(ItemEOF)
+		return false
+	}
+	return true
+}
+
+// bindings lexes the fields of a record by iterating over the Record's
+// States, returning true if the record failed.
+func (l *Lexer) bindings() (failed bool) {
+	eor := len(l.rec.States) - 1
+	for i, state := range l.rec.States {
+		if l.rec.Salvage && i > 0 && l.Peek() == EOF {
+			l.truncate()
+			return true
+		}
+		if !l.state(i, state) {
+			return true
+		}
+		if i == eor && !l.terminate() {
+			return true
+		}
+		if i == eor || (l.eof && !l.rec.Salvage) {
+			l.Emit(ItemEOR)
+		}
+	}
+	return false
 }
 
 // NextItem returns the next Item from the input.
 func (l *Lexer) NextItem() Item {
-	item := <-l.items
-	l.lastPos = item.Pos
+	item, _ := l.nextItem()
 	return item
 }
 
+// nextItem returns the next Item from the input and true or, once
+// there are no more, the Item that NextItem reports in their place and
+// false.
+func (l *Lexer) nextItem() (Item, bool) {
+	if l.items == nil {
+		if !l.Scan() {
+			if l.canceled() {
+				return l.canceledItem(), false
+			}
+			return Item{}, false
+		}
+		return l.item, true
+	}
+	item, ok := <-l.items
+	if !ok && l.canceled() {
+		return l.canceledItem(), false
+	}
+	l.lastPos = item.Pos
+	return item, ok
+}
+
+// Drain consumes and discards the remaining items until the Lexer's
+// goroutine has finished, allowing a client that stops reading early
+// to release the goroutine without abandoning it mid-record.  Drain
+// reads the input through to its end.
+func (l *Lexer) Drain() {
+	if l.items == nil {
+		for l.Scan() {
+		}
+		return
+	}
+	for item := range l.items {
+		l.lastPos = item.Pos
+	}
+}
+
 // LastPos returns the position of the most recent Item read from the input
 func (l *Lexer) LastPos() int64 {
 	return l.lastPos
 }
 
-// Errorf returns an error token
+// Errorf returns an error token, whose cause is a *SyntaxError
 func (l *Lexer) Errorf(format string, args ...interface{}) {
-	l.items <- Item{ItemError, l.rpos, fmt.Sprintf(format, args...)}
+	if l.trial > 0 {
+		return
+	}
+	l.Fail(&SyntaxError{Pos: l.rpos, Msg: fmt.Sprintf(format, args...)})
+}
+
+// Fail returns an error token for err, whose message becomes the
+// token's value and which is carried as its Err.Cause, so that clients
+// can tell errors apart without parsing their messages.
+func (l *Lexer) Fail(err error) {
+	if l.trial > 0 {
+		return
+	}
+	msg := err.Error()
+	if l.rec.Context > 0 {
+		msg += l.errorContext(l.rec.Context)
+	}
+	if l.rec.Mask != nil {
+		// the message, and its excerpt, may quote the input.
+		msg = l.rec.Mask(msg)
+		if e, ok := err.(*SyntaxError); ok {
+			err = &SyntaxError{Pos: e.Pos, Msg: l.rec.Mask(e.Msg)}
+		}
+	}
+	item := Item{Type: ItemError, Pos: l.rpos, Value: msg, Err: l.errorState()}
+	item.Err.Cause = err
+	if l.hold {
+		l.held = append(l.held, item)
+		return
+	}
+	l.send(item)
+}
+
+// errorState returns the Error describing the Binding that is running.
+func (l *Lexer) errorState() Error {
+	if l.running == 0 {
+		return Error{State: -1}
+	}
+	b := l.rec.States[l.running-1]
+	name, ok := l.rec.name(b.ItemType)
+	if !ok {
+		name = funcName(b.StateFn)
+	}
+	return Error{State: l.running - 1, Binding: name}
 }
 
 // Next consumes the next rune in the input.
 func (l *Lexer) Next() rune {
+	if l.checker != nil {
+		l.checker.next()
+	}
 	// read more of the input if we've reached the end of the
 	// buffer or if we might be on a character boundry.
 	if (len(l.buf) - l.pos) < utf8.UTFMax {
-		n, err := l.r.Read(l.next)
+		l.gate.wait(l.done())
+		if l.canceled() {
+			l.eof = true
+			return EOF
+		}
+		n, err := l.r.Read(l.chunk())
 		if err != nil && err != io.EOF {
-			l.Errorf("%s: %v", l.name, err)
+			if l.err == nil {
+				l.err = err
+			}
+			l.Fail(&ReadError{Name: l.name, Err: err})
 		} else if n > 0 {
 			l.buf = append(l.buf, l.next[0:n]...)
 		}
@@ -248,6 +606,34 @@ func (l *Lexer) Bytes() []byte {
 	return l.buf[l.start:l.pos]
 }
 
+// tokenPos returns the position in the input of the start of the
+// current token.
+func (l *Lexer) tokenPos() int64 {
+	return l.rpos - int64(l.pos-l.start)
+}
+
+// Token returns the starting position, in bytes, of the current token
+// and a view of the bytes consumed so far.  The returned slice shares
+// the Lexer's buffer and is only valid until the next call to Emit,
+// Skip, or Next; callers that need to retain it must make a copy.
+func (l *Lexer) Token() (start int64, bytes []byte) {
+	return l.tokenPos(), l.buf[l.start:l.pos]
+}
+
+// LastRune returns the last rune of the current token and its width
+// in bytes.  If nothing has been consumed it returns (EOF, 0).
+func (l *Lexer) LastRune() (rune, int) {
+	if l.pos == l.start {
+		return EOF, 0
+	}
+	return utf8.DecodeLastRune(l.buf[l.start:l.pos])
+}
+
+// RuneCount returns the number of runes in the current token.
+func (l *Lexer) RuneCount() int {
+	return utf8.RuneCount(l.buf[l.start:l.pos])
+}
+
 // Accept consumes the next rune if it in the valid set, returning true on success.
 func (l *Lexer) Accept(valid string) bool {
 	if strings.IndexRune(valid, l.Next()) >= 0 {
@@ -298,6 +684,9 @@ func (l *Lexer) ExceptRun(invalid string) bool {
 
 // Backup steps back one rune.  Can only be called once per call of Next.
 func (l *Lexer) Backup() {
+	if l.checker != nil {
+		l.checker.backup()
+	}
 	if !l.eof {
 		l.pos -= l.width
 		l.rpos -= int64(l.width)
@@ -306,19 +695,126 @@ func (l *Lexer) Backup() {
 
 // Emit reports the current item to the client
 func (l *Lexer) Emit(t ItemType) {
-	l.items <- Item{t, l.rpos - int64(l.pos-l.start), string(l.buf[l.start:l.pos])}
+	if l.capture == nil {
+		if l.fields != nil && t > ItemEOF && !l.rewrites() {
+			l.fill(t, l.buf[l.start:l.pos])
+			l.Skip()
+			return
+		}
+		if l.fields == nil && l.suppressed(t) {
+			l.Skip()
+			return
+		}
+	}
+	item := Item{Type: t, Pos: l.rpos - int64(l.pos-l.start), Value: l.value(l.buf[l.start:l.pos])}
+	if l.rec.ZeroCopy {
+		item.Bytes = l.buf[l.start:l.pos:l.pos]
+	}
+	l.emit(item)
+	l.Skip()
+}
+
+// EmitValue reports the current item to the client using value in
+// place of the consumed bytes.  This allows a StateFn to deliver a
+// normalized form of the token while still advancing past it.
+func (l *Lexer) EmitValue(t ItemType, value string) {
+	l.emit(Item{Type: t, Pos: l.rpos - int64(l.pos-l.start), Value: value})
 	l.Skip()
 }
 
+// emit sends item to the client, applying either the encryption of
+// an enclosing Encrypt StateFn or the Record's Mask to its value.
+// While ScanRecord runs, the value is instead copied into the
+// caller's buffer for its type, if any.  Items whose type is left out
+// by the Record's Only set are dropped.  Neither applies to items
+// taken by a capture function, such as that of Embed, which are the
+// raw material of other items rather than output.
+func (l *Lexer) emit(item Item) {
+	if l.trial > 0 {
+		return
+	}
+	if l.capture == nil && l.fields == nil && l.suppressed(item.Type) {
+		return
+	}
+	if l.sanitizer != nil {
+		var ok bool
+		if item, ok = l.sanitize(item); !ok {
+			return
+		}
+	}
+	if l.encrypt != nil {
+		ciphertext, err := l.encrypt([]byte(item.Value))
+		if err != nil {
+			l.encErr = true
+			state := l.errorState()
+			state.Cause = err
+			l.send(Item{Type: ItemError, Pos: item.Pos, Value: fmt.Sprintf("%s: encrypt: %v", l.name, err), Err: state})
+			return
+		}
+		item.Value = base64.RawURLEncoding.EncodeToString(ciphertext)
+		item.Bytes = nil
+	} else if l.rec.Mask != nil && item.Value != "" {
+		item.Value = l.rec.Mask(item.Value)
+		item.Bytes = nil
+	}
+	if l.rec.Sketches != nil {
+		l.sketch(item)
+	}
+	switch {
+	case l.capture != nil:
+		l.capture(item)
+	case l.fields != nil && item.Type > ItemEOF:
+		l.fillString(item.Type, item.Value)
+	default:
+		l.send(item)
+	}
+}
+
+// send delivers item to the client, either over the items channel or,
+// if set, to the Lexer's out function.
+func (l *Lexer) send(item Item) {
+	if l.batch != nil {
+		l.batch.add(item)
+	}
+	if l.rec.PosMap != nil {
+		item = l.mapPos(item)
+	}
+	if l.out != nil {
+		l.out(item)
+		return
+	}
+	if l.ctx == nil {
+		l.items <- item
+		return
+	}
+	// once canceled, deliver nothing more, even to a client that
+	// is still reading.
+	select {
+	case <-l.ctx.Done():
+		return
+	default:
+	}
+	select {
+	case l.items <- item:
+	case <-l.ctx.Done():
+	}
+}
+
+// captureFn receives items emitted while it is installed as a Lexer's
+// capture function.
+type captureFn func(item Item)
+
 // Skip advances over the current item without reporting it
 func (l *Lexer) Skip() {
 	// We're at a point where we know we have completely read a
 	// token.  If we've read 90% of an l.buf's capacity, shift the
 	// unread content to the start of the buffer.  Otherwise just
-	// move l.start to the current position.
+	// move l.start to the current position.  While trying
+	// StateFns, or stepping, the buffer is never shifted, so that
+	// the consumed bytes remain available.
 	n := cap(l.buf)
 	r := n - l.pos
-	if n/10 >= r {
+	if n/10 >= r && l.trial == 0 && !l.keep {
 		l.buf, l.start, l.pos = append(l.buf[0:0], l.buf[l.pos:]...), 0, 0
 	} else {
 		l.start = l.pos
@@ -353,7 +849,7 @@ func Accept(valid string, needed bool) StateFn {
 			return true
 		}
 		if needed {
-			l.Errorf("expected character from the set %q, got %q", valid, l.Peek())
+			l.unexpected(fmt.Sprintf("character from the set %q", valid))
 		}
 		return false
 	}
@@ -373,7 +869,7 @@ func AcceptRun(valid string, needed bool) StateFn {
 			return true
 		}
 		if needed {
-			l.Errorf("expected a run of characters from the set %q, got %q", valid, l.Peek())
+			l.unexpected(fmt.Sprintf("a run of characters from the set %q", valid))
 		}
 		return false
 	}
@@ -393,7 +889,7 @@ func Except(invalid string, needed bool) StateFn {
 			return true
 		}
 		if needed {
-			l.Errorf("expected a character outside the set %q, got %q", invalid, l.Peek())
+			l.unexpected(fmt.Sprintf("a character outside the set %q", invalid))
 		}
 		return false
 	}
@@ -413,7 +909,7 @@ func ExceptRun(invalid string, needed bool) StateFn {
 			return true
 		}
 		if needed {
-			l.Errorf("expected a character outside the set %q, got %q", invalid, l.Peek())
+			l.unexpected(fmt.Sprintf("a character outside the set %q", invalid))
 		}
 		return false
 	}
@@ -427,7 +923,7 @@ func ExceptRun(invalid string, needed bool) StateFn {
 func Quote(l *Lexer, t ItemType, emit bool) (success bool) {
 	r := l.Next()
 	if r != '"' {
-		l.Errorf("expected '\"', got %q", r)
+		l.Fail(&UnexpectedRuneError{Pos: l.rpos, Rune: r, Expected: `'"'`})
 		l.Backup()
 		return false
 	}
@@ -436,11 +932,11 @@ func Quote(l *Lexer, t ItemType, emit bool) (success bool) {
 		case '\\':
 			l.Next()
 		case '\n':
-			l.Errorf("unterminated quote")
+			l.Fail(&UnterminatedQuoteError{Pos: l.tokenPos()})
 			l.Backup()
 			return false
 		case EOF:
-			l.Errorf("unterminated quote")
+			l.Fail(&UnterminatedQuoteError{Pos: l.tokenPos()})
 			return false
 		case '"':
 			if emit {
@@ -453,11 +949,12 @@ func Quote(l *Lexer, t ItemType, emit bool) (success bool) {
 	}
 }
 
-// Digits consumes unicode digits
+// Digits consumes digits, as defined by the Record's Classifier
 func Digits(l *Lexer, t ItemType, emit bool) (success bool) {
+	c := l.Classifier()
 	for {
 		r := l.Next()
-		if !unicode.IsDigit(r) {
+		if !c.IsDigit(r) {
 			l.Backup()
 			if l.pos > l.start {
 				if emit {
@@ -467,17 +964,18 @@ func Digits(l *Lexer, t ItemType, emit bool) (success bool) {
 				}
 				return true
 			}
-			l.Errorf("expected [0-9], got %q", r)
+			l.Fail(&UnexpectedRuneError{Pos: l.rpos, Rune: r, Expected: "[0-9]"})
 			return false
 		}
 	}
 }
 
-// Letters consumes unicode letters
+// Letters consumes letters, as defined by the Record's Classifier
 func Letters(l *Lexer, t ItemType, emit bool) (success bool) {
+	c := l.Classifier()
 	for {
 		r := l.Next()
-		if !unicode.IsLetter(r) {
+		if !c.IsLetter(r) {
 			l.Backup()
 			if l.pos > l.start {
 				if emit {
@@ -487,17 +985,18 @@ func Letters(l *Lexer, t ItemType, emit bool) (success bool) {
 				}
 				return true
 			}
-			l.Errorf("expected letter, got %q", r)
+			l.Fail(&UnexpectedRuneError{Pos: l.rpos, Rune: r, Expected: "letter"})
 			return false
 		}
 	}
 }
 
-// Spaces consumes unicode spaces
+// Spaces consumes whitespace, as defined by the Record's Classifier
 func Spaces(l *Lexer, t ItemType, emit bool) (success bool) {
+	c := l.Classifier()
 	for {
 		r := l.Next()
-		if !unicode.IsSpace(r) {
+		if !c.IsSpace(r) {
 			l.Backup()
 			if l.pos > l.start {
 				if emit {
@@ -507,7 +1006,7 @@ func Spaces(l *Lexer, t ItemType, emit bool) (success bool) {
 				}
 				return true
 			}
-			l.Errorf("expected whitespace, got %q", r)
+			l.Fail(&UnexpectedRuneError{Pos: l.rpos, Rune: r, Expected: "whitespace"})
 			return false
 		}
 	}
@@ -523,7 +1022,11 @@ func Number(l *Lexer, t ItemType, emit bool) bool {
 	}
 	if sign := l.Peek(); sign == '+' || sign == '-' {
 		// Complex: 1+2i. No spaces, must end in 'i'.
-		if !l.scanNumber() || l.buf[l.pos-1] != 'i' {
+		if !l.scanNumber() {
+			l.Errorf("bad number syntax: %q", l.buf[l.start:l.pos])
+			return false
+		}
+		if r, _ := l.LastRune(); r != 'i' {
 			l.Errorf("bad number syntax: %q", l.buf[l.start:l.pos])
 			return false
 		}
@@ -555,14 +1058,16 @@ func (l *Lexer) scanNumber() bool {
 	// Is it imaginary?
 	l.Accept("i")
 	// Next thing mustn't be alphanumeric.
-	if isAlphaNumeric(l.Peek()) {
+	if l.isAlphaNumeric(l.Peek()) {
 		l.Next()
 		return false
 	}
 	return true
 }
 
-// isAlphaNumeric reports whether r is an alphabetic, digit, or underscore.
-func isAlphaNumeric(r rune) bool {
-	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+// isAlphaNumeric reports whether r is an alphabetic, digit, or
+// underscore, according to the Lexer's Classifier.
+func (l *Lexer) isAlphaNumeric(r rune) bool {
+	c := l.Classifier()
+	return r == '_' || c.IsLetter(r) || c.IsDigit(r)
 }
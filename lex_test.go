@@ -1,6 +1,7 @@
 package lexrec
 
 import (
+	"bytes"
 	//"fmt"
 	"strings"
 	"testing"
@@ -113,3 +114,131 @@ func TestLexerSkipPast(t *testing.T) {
 		t.Fatalf("expected ItemEmit of one character 'a', got %q", item.Value)
 	}
 }
+
+func TestLexerToken(t *testing.T) {
+	r := strings.NewReader("bbaa")
+	l, err := NewLexerRun("TestLexerToken", r, aRecord, func(l *Lexer) {
+		l.AcceptRun("b")
+		l.Skip()
+		l.AcceptRun("a")
+		start, b := l.Token()
+		if start != 2 {
+			t.Errorf("expected token start 2, got %d", start)
+		}
+		if string(b) != "aa" {
+			t.Errorf("expected token \"aa\", got %q", b)
+		}
+		l.Emit(ItemEOF)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for item := l.NextItem(); item.Type != ItemEOF; item = l.NextItem() {
+		if item.Type == ItemError {
+			t.Fatal(item.Value)
+		}
+	}
+}
+
+func TestLexerLastRune(t *testing.T) {
+	r := strings.NewReader("aé")
+	rec := NewRecord(16, aRecord.States, aRecord.ErrorFn)
+	l, err := NewLexerRun("TestLexerLastRune", r, rec, func(l *Lexer) {
+		if r, w := l.LastRune(); r != EOF || w != 0 {
+			t.Errorf("expected (EOF, 0) for empty token, got (%q, %d)", r, w)
+		}
+		l.Next()
+		l.Next()
+		if r, w := l.LastRune(); r != 'é' || w != 2 {
+			t.Errorf("expected ('é', 2), got (%q, %d)", r, w)
+		}
+		if n := l.RuneCount(); n != 2 {
+			t.Errorf("expected 2 runes, got %d", n)
+		}
+		l.Emit(ItemEOF)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for item := l.NextItem(); item.Type != ItemEOF; item = l.NextItem() {
+	}
+}
+
+// sameItem reports whether a and b are equal, comparing the contents
+// of their Bytes.
+func sameItem(a, b Item) bool {
+	return a.Type == b.Type && a.Pos == b.Pos && a.Value == b.Value && a.Err == b.Err && bytes.Equal(a.Bytes, b.Bytes)
+}
+
+// lexAll returns every item lexed from input using rec, up to and
+// including the ItemEOF.
+func lexAll(t *testing.T, name string, input string, rec Record) []Item {
+	l, err := NewLexer(name, strings.NewReader(input), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []Item{}
+	for {
+		item := l.NextItem()
+		items = append(items, item)
+		if item.Type == ItemEOF {
+			break
+		}
+	}
+	return items
+}
+
+func TestLexerDrain(t *testing.T) {
+	r := strings.NewReader("a\na\na\n")
+	l, err := NewLexer("TestLexerDrain", r, aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.NextItem()
+	l.Drain()
+	if !finished(l) {
+		t.Errorf("expected items channel to be closed after Drain")
+	}
+	if r.Len() != 0 {
+		t.Errorf("expected input to be consumed, %d bytes remain", r.Len())
+	}
+}
+
+func TestLexerErrorState(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Names:   NameMap{ItemB: "count"},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestLexerErrorState", "a x\n b\n", rec)
+	var errs []Error
+	for _, item := range items {
+		if item.Type == ItemError {
+			errs = append(errs, item.Err)
+		}
+	}
+	expect := []Error{{State: 2, Binding: "count"}, {State: 0, Binding: "lexrec.Letters"}}
+	if len(errs) != len(expect) {
+		t.Fatalf("expected errors %v, got %v", expect, errs)
+	}
+	for i := range expect {
+		if errs[i].State != expect[i].State || errs[i].Binding != expect[i].Binding {
+			t.Errorf("expected error %v, got %v", expect[i], errs[i])
+		}
+	}
+}
+
+// finished reports whether the goroutine lexing l, if it has one, has
+// finished and closed its channel.
+func finished(l *Lexer) bool {
+	if l.items == nil {
+		return true
+	}
+	_, ok := <-l.items
+	return !ok
+}
@@ -0,0 +1,97 @@
+package lexrec
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// LineStats collects statistics on the length of records, and
+// optionally flags records whose length is anomalous compared to the
+// records before them, e.g., lines truncated or concatenated by a
+// logging bug.  Set a Record's LineStats to a *LineStats to collect
+// them.  The statistics are updated by the Lexer's goroutine, and are
+// only safe to read once ItemEOF has been received.
+type LineStats struct {
+	Factor    float64   // if > 0, flag records more than Factor times longer or shorter than the running median
+	Window    int       // number of recent records the running median is taken over; 0 means 1000
+	Records   int64     // records seen, including failed records
+	Total     int64     // total length of the records, in bytes
+	Min       int64     // length of the shortest record
+	Max       int64     // length of the longest record
+	Anomalies int64     // records flagged as anomalous
+	Histogram [64]int64 // Histogram[i] counts records whose length needs i bits, i.e., is less than 1<<i
+	recent    []int64   // lengths of recent records, oldest first
+	sorted    []int64   // recent, sorted
+}
+
+// minMedian is the number of records that must have been seen before
+// lengths are compared against the running median.
+const minMedian = 10
+
+// Mean returns the mean record length.
+func (s *LineStats) Mean() float64 {
+	if s.Records == 0 {
+		return 0
+	}
+	return float64(s.Total) / float64(s.Records)
+}
+
+// Median returns the median length of the recent records.
+func (s *LineStats) Median() int64 {
+	if len(s.sorted) == 0 {
+		return 0
+	}
+	return s.sorted[len(s.sorted)/2]
+}
+
+// add records a record of n bytes, returning true if it is anomalous.
+func (s *LineStats) add(n int64) (anomalous bool) {
+	if s.Factor > 0 && len(s.sorted) >= minMedian {
+		m := float64(s.Median())
+		anomalous = float64(n) > s.Factor*m || float64(n)*s.Factor < m
+	}
+	if anomalous {
+		s.Anomalies++
+	}
+	if s.Records == 0 || n < s.Min {
+		s.Min = n
+	}
+	if n > s.Max {
+		s.Max = n
+	}
+	s.Records++
+	s.Total += n
+	s.Histogram[bits.Len64(uint64(n))]++
+
+	window := s.Window
+	if window <= 0 {
+		window = 1000
+	}
+	if len(s.recent) == window {
+		old := s.recent[0]
+		s.recent = append(s.recent[:0], s.recent[1:]...)
+		i := sort.Search(len(s.sorted), func(i int) bool { return s.sorted[i] >= old })
+		s.sorted = append(s.sorted[:i], s.sorted[i+1:]...)
+	}
+	s.recent = append(s.recent, n)
+	i := sort.Search(len(s.sorted), func(i int) bool { return s.sorted[i] >= n })
+	s.sorted = append(s.sorted, 0)
+	copy(s.sorted[i+1:], s.sorted[i:])
+	s.sorted[i] = n
+	return anomalous
+}
+
+// lineStats adds the record that began at start to the Record's
+// LineStats, emitting an ItemAnomaly if its length is anomalous.
+func (l *Lexer) lineStats(start int64) {
+	n := l.tokenPos() - start
+	if n == 0 {
+		return
+	}
+	s := l.rec.LineStats
+	median := s.Median()
+	if s.add(n) {
+		l.send(Item{Type: ItemAnomaly, Pos: start, Value: fmt.Sprintf("record of %d bytes, running median %d", n, median)})
+	}
+}
@@ -0,0 +1,38 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineStats(t *testing.T) {
+	stats := &LineStats{Factor: 3}
+	rec := Record{
+		Buflen:    64,
+		ErrorFn:   SkipPast("\n"),
+		LineStats: stats,
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	input := strings.Repeat("abcd\n", 10) + "abcdefghijklmnopqrstuvwxyz\n" + "abc\n"
+	items := lexAll(t, "TestLineStats", input, rec)
+	var anomalies []Item
+	for _, item := range items {
+		if item.Type == ItemAnomaly {
+			anomalies = append(anomalies, item)
+		}
+	}
+	if len(anomalies) != 1 || anomalies[0].Pos != 50 {
+		t.Errorf("expected one anomaly at 50, got %v", anomalies)
+	}
+	if stats.Records != 12 || stats.Min != 4 || stats.Max != 27 || stats.Anomalies != 1 {
+		t.Errorf("expected 12 records of 4 to 27 bytes with 1 anomaly, got %+v", *stats)
+	}
+	if stats.Histogram[3] != 11 || stats.Histogram[5] != 1 {
+		t.Errorf("expected 11 records under 8 bytes and 1 under 32, got %v", stats.Histogram)
+	}
+	if m := stats.Median(); m != 5 {
+		t.Errorf("expected a median of 5, got %d", m)
+	}
+}
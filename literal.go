@@ -0,0 +1,24 @@
+package lexrec
+
+import (
+	"fmt"
+)
+
+// Literal returns a StateFn that consumes the exact, non-empty,
+// sequence of bytes s, e.g., Literal(`" - - [`) or Literal("HTTP/").
+// On a partial match nothing is consumed, and an error is emitted.
+func Literal(s string) StateFn {
+	expected := fmt.Sprintf("%q", s)
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		if !l.acceptString(s) {
+			l.unexpected(expected)
+			return false
+		}
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
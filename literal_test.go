@@ -0,0 +1,31 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestLiteral(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Literal(" - - ["), false},
+			{ItemB, Literal("HTTP/"), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestLiteral", "ab - - [HTTP/\ncd - -x\nef - - [HTTPS\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemB, Pos: 8, Value: "HTTP/"}, {Type: ItemEOR, Pos: 14},
+		{Type: ItemA, Pos: 14, Value: "cd"}, {Type: ItemError, Pos: 16},
+		{Type: ItemA, Pos: 22, Value: "ef"}, {Type: ItemError, Pos: 30},
+		{Type: ItemEOF, Pos: 36}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+	for _, item := range items {
+		if item.Type == ItemError && item.Pos != 16 && item.Pos != 30 {
+			t.Errorf("expected the error at the start of the literal, got %v", item)
+		}
+	}
+}
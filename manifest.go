@@ -0,0 +1,81 @@
+package lexrec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Span describes the extent of a record in the input.
+type Span struct {
+	Record int64 // record number, starting at 1
+	Start  int64 // position, in bytes, of the start of the record
+	Len    int64 // length of the record in bytes, including any bytes skipped by ErrorFn
+	Err    bool  // true if the record failed to lex
+}
+
+// SpanFn is a function that is called with the Span of each record
+// once the record has been lexed, before any items of the following
+// record are emitted.  It is run from the Lexer's goroutine.
+type SpanFn func(s Span)
+
+// StartFn is a function that is called with the number and starting
+// position of each record, before any of its items are emitted.
+// Together with a SpanFn, called once the record has been lexed, it
+// brackets each record, e.g., for timing or batching.  It is run from
+// the Lexer's goroutine.
+type StartFn func(record, start int64)
+
+// span reports the record that began at start to the Record's OnSpan
+// function.
+func (l *Lexer) span(start int64, failed bool) {
+	end := l.tokenPos()
+	if end == start {
+		return
+	}
+	l.nrec++
+	if l.rec.OnSpan != nil {
+		l.rec.OnSpan(Span{Record: l.nrec, Start: start, Len: end - start, Err: failed})
+	}
+}
+
+// Manifest writes one line per record, holding the tab separated
+// record number, start offset, byte length, and an error flag (0 or
+// 1), for use in random access and auditing.  Its Span method is
+// suitable for use as a Record's OnSpan function:
+//
+//	m := lexrec.NewManifest(w)
+//	rec.OnSpan = m.Span
+//
+// Flush must be called once the Lexer has emitted ItemEOF.
+type Manifest struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewManifest returns a Manifest that writes to w.
+func NewManifest(w io.Writer) *Manifest {
+	return &Manifest{w: bufio.NewWriter(w)}
+}
+
+// Span writes the manifest line for s.  Once a write fails all
+// further lines are discarded and the error is returned by Flush.
+func (m *Manifest) Span(s Span) {
+	if m.err != nil {
+		return
+	}
+	flag := 0
+	if s.Err {
+		flag = 1
+	}
+	_, m.err = fmt.Fprintf(m.w, "%d\t%d\t%d\t%d\n", s.Record, s.Start, s.Len, flag)
+}
+
+// Flush writes any buffered lines to the underlying writer, returning
+// the first error encountered.
+func (m *Manifest) Flush() error {
+	if m.err != nil {
+		return m.err
+	}
+	return m.w.Flush()
+}
@@ -0,0 +1,51 @@
+package lexrec
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	buf := new(bytes.Buffer)
+	m := NewManifest(buf)
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		OnSpan:  m.Span,
+		States: []Binding{
+			{ItemEmit, acceptRunA, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	lexAll(t, "TestManifest", "aaa\nbb\na\n", rec)
+	if err := m.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	expect := "1\t0\t4\t0\n2\t4\t3\t1\n3\t7\t2\t0\n"
+	if buf.String() != expect {
+		t.Errorf("expected manifest %q, got %q", expect, buf.String())
+	}
+}
+
+func TestOnStart(t *testing.T) {
+	var events []string
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		OnStart: func(record, start int64) {
+			events = append(events, fmt.Sprintf("start %d@%d", record, start))
+		},
+		OnSpan: func(s Span) {
+			events = append(events, fmt.Sprintf("end %d@%d+%d", s.Record, s.Start, s.Len))
+		},
+		States: []Binding{
+			{ItemEmit, acceptRunA, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	lexAll(t, "TestOnStart", "aaa\nbb\na\n", rec)
+	expect := "start 1@0 end 1@0+4 start 2@4 end 2@4+3 start 3@7 end 3@7+2"
+	if got := strings.Join(events, " "); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+}
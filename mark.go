@@ -0,0 +1,45 @@
+package lexrec
+
+// Mark is a checkpoint of a Lexer's position within its current
+// token, returned by Mark and restored by Rewind.
+type Mark struct {
+	start int64 // position of the start of the token
+	rpos  int64 // position of the next rune
+	width int   // width of the last rune read
+	eof   bool  // whether the end of the input had been reached
+}
+
+// Mark returns a checkpoint of the current position, to which Rewind
+// can return the Lexer, e.g., so that a StateFn can try one parse and
+// fall back to another:
+//
+//	func timestampOrDash(l *lexrec.Lexer, t lexrec.ItemType, emit bool) bool {
+//		m := l.Mark()
+//		if l.AcceptRun("0123456789") && l.Accept("T") && l.AcceptRun("0123456789:") {
+//			l.Emit(t)
+//			return true
+//		}
+//		l.Rewind(m)
+//		if l.Accept("-") {
+//			l.Emit(t)
+//			return true
+//		}
+//		l.Errorf("expected a timestamp or '-', got %q", l.Peek())
+//		return false
+//	}
+func (l *Lexer) Mark() Mark {
+	return Mark{l.tokenPos(), l.rpos, l.width, l.eof}
+}
+
+// Rewind returns l to the position checkpointed by m.  Only positions
+// within the current token can be returned to: once the token has been
+// emitted or skipped, Rewind leaves l where it is and returns false.
+// Items and errors reported since the Mark are not withdrawn.
+func (l *Lexer) Rewind(m Mark) bool {
+	if m.start != l.tokenPos() {
+		return false
+	}
+	l.pos -= int(l.rpos - m.rpos)
+	l.rpos, l.width, l.eof = m.rpos, m.width, m.eof
+	return true
+}
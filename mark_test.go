@@ -0,0 +1,67 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+// timestampOrDash is a StateFn that consumes either digits followed by
+// a 'T' and more digits and colons, or a single '-'.
+func timestampOrDash(l *Lexer, t ItemType, emit bool) bool {
+	m := l.Mark()
+	if l.AcceptRun("0123456789") && l.Accept("T") && l.AcceptRun("0123456789:") {
+		l.Emit(t)
+		return true
+	}
+	l.Rewind(m)
+	if l.Accept("-") {
+		l.Emit(t)
+		return true
+	}
+	l.Errorf("expected a timestamp or '-', got %q", l.Peek())
+	return false
+}
+
+func TestMarkRewind(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, timestampOrDash, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestMarkRewind", "2024T12:00\n-\n2024-\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: "2024T12:00"}, {Type: ItemEOR, Pos: 11},
+		{Type: ItemA, Pos: 11, Value: "-"}, {Type: ItemEOR, Pos: 13},
+		{Type: ItemError, Pos: 13},
+		{Type: ItemEOF, Pos: 19}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+}
+
+func TestRewindStale(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, func(l *Lexer, it ItemType, emit bool) bool {
+				m := l.Mark()
+				l.AcceptRun("ab")
+				if !l.Rewind(m) || l.Size() != 0 {
+					t.Errorf("expected Rewind to return to the start of the token")
+				}
+				l.AcceptRun("ab")
+				l.Emit(it)
+				if l.Rewind(m) {
+					t.Errorf("expected Rewind to fail once the token was emitted")
+				}
+				return true
+			}, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestRewindStale", "abab\n", rec)
+	if len(items) != 3 || items[0].Value != "abab" {
+		t.Errorf("expected abab, got %v", items)
+	}
+}
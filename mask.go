@@ -0,0 +1,90 @@
+package lexrec
+
+// MaskFn is a function that rewrites the value of an item before it
+// is emitted, typically to remove sensitive data.
+type MaskFn func(value string) string
+
+// MaskPAN is a MaskFn that replaces the digits of anything that looks
+// like a payment card number (PAN) with '*', leaving the last four
+// digits visible.  A candidate PAN is a run of 13 to 19 digits,
+// optionally grouped by single spaces or dashes, that passes the Luhn
+// checksum.  Values holding no candidates are returned unchanged.
+func MaskPAN(value string) string {
+	var masked []byte
+	for i := 0; i < len(value); {
+		if !isASCIIDigit(rune(value[i])) {
+			i++
+			continue
+		}
+		// collect the offsets of a run of digits, allowing a
+		// single space or dash between digits.
+		var offsets [20]int
+		n := 0
+		j := i
+		for j < len(value) {
+			if isASCIIDigit(rune(value[j])) {
+				if n < len(offsets) {
+					offsets[n] = j
+				}
+				n++
+				j++
+			} else if (value[j] == ' ' || value[j] == '-') && j+1 < len(value) && isASCIIDigit(rune(value[j+1])) {
+				j++
+			} else {
+				break
+			}
+		}
+		if n >= 13 && n <= 19 && luhn(value, offsets[:n]) {
+			if masked == nil {
+				masked = []byte(value)
+			}
+			for _, off := range offsets[:n-4] {
+				masked[off] = '*'
+			}
+		}
+		i = j
+	}
+	if masked == nil {
+		return value
+	}
+	return string(masked)
+}
+
+// luhn reports whether the digits of s found at offsets pass the Luhn
+// checksum.
+func luhn(s string, offsets []int) bool {
+	sum := 0
+	double := false
+	for i := len(offsets) - 1; i >= 0; i-- {
+		d := int(s[offsets[i]] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// EncryptFn encrypts the value of an item.  Implementations that need
+// the ciphertext to be joinable across records should be
+// deterministic, e.g., AES-SIV with a fixed key.
+type EncryptFn func(plaintext []byte) (ciphertext []byte, err error)
+
+// Encrypt returns a StateFn that runs fn, replacing the value of each
+// item it emits with enc(value) encoded using unpadded base64url.
+// Encrypted values are not passed through the Record's Mask.  If enc
+// returns an error, an error is emitted in place of the item and the
+// StateFn fails.
+func Encrypt(fn StateFn, enc EncryptFn) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		prev := l.encrypt
+		l.encrypt, l.encErr = enc, false
+		success := fn(l, t, emit)
+		l.encrypt = prev
+		return success && !l.encErr
+	}
+}
@@ -0,0 +1,96 @@
+package lexrec
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMaskPAN(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"card 4111111111111111 ok", "card ************1111 ok"},
+		{"4111-1111-1111-1111", "****-****-****-1111"},
+		{"4111 1111 1111 1112", "4111 1111 1111 1112"},
+		{"order 123456", "order 123456"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := MaskPAN(test.input); got != test.expect {
+			t.Errorf("MaskPAN(%q): expected %q, got %q", test.input, test.expect, got)
+		}
+	}
+}
+
+func TestRecordMask(t *testing.T) {
+	rec := Record{
+		Buflen:  32,
+		ErrorFn: SkipPast("\n"),
+		Mask:    MaskPAN,
+		States: []Binding{
+			{ItemEmit, ExceptRun("\n", true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestRecordMask", "pan=5500000000000004\n", rec)
+	if items[0].Value != "pan=************0004" {
+		t.Errorf("expected masked PAN, got %q", items[0].Value)
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	reverse := func(p []byte) ([]byte, error) {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("empty value")
+		}
+		c := make([]byte, len(p))
+		for i := range p {
+			c[len(p)-1-i] = p[i]
+		}
+		return c, nil
+	}
+	rec := Record{
+		Buflen:  32,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Encrypt(ExceptRun(" \n", true), reverse), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, ExceptRun("\n", true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestEncrypt", "abc def\n", rec)
+	if expect := base64.RawURLEncoding.EncodeToString([]byte("cba")); items[0].Value != expect {
+		t.Errorf("expected encrypted value %q, got %q", expect, items[0].Value)
+	}
+	if items[1].Value != "def" {
+		t.Errorf("expected plaintext value \"def\", got %q", items[1].Value)
+	}
+}
+
+func TestRecordMaskError(t *testing.T) {
+	badNumber := func(l *Lexer, t ItemType, emit bool) bool {
+		l.AcceptRun("0123456789x")
+		l.Errorf("bad number syntax: %q", l.Bytes())
+		return false
+	}
+	rec := Record{
+		Buflen:  64,
+		ErrorFn: SkipPast("\n"),
+		Mask:    MaskPAN,
+		Context: 40,
+		States: []Binding{
+			{ItemEmit, badNumber, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestRecordMaskError", "5500000000000004x\n", rec)
+	if items[0].Type != ItemError {
+		t.Fatalf("expected ItemError, got %q", items[0])
+	}
+	for _, msg := range []string{items[0].Value, items[0].Err.Cause.Error()} {
+		if strings.Contains(msg, "5500000000000004") || !strings.Contains(msg, "************0004") {
+			t.Errorf("expected masked PAN, got %q", msg)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package lexrec
+
+import (
+	"fmt"
+)
+
+// builtinNames holds the names of the item types generated by the
+// Lexer itself.  The names of a caller's item types are scoped to
+// their Record, in its Names, as different Records may reuse the same
+// ItemType values.
+var builtinNames = map[ItemType]string{
+	ItemError:     "Error",
+	ItemEOR:       "EOR",
+	ItemEOF:       "EOF",
+	ItemTruncated: "Truncated",
+	ItemRecovered: "Recovered",
+	ItemAnomaly:   "Anomaly",
+	ItemTorn:      "Torn",
+	ItemWarning:   "Warning",
+}
+
+// name returns the name of t from the Record's Names or, for a
+// built-in item type, its built-in name.
+func (rec Record) name(t ItemType) (string, bool) {
+	if name, ok := rec.Names[t]; ok {
+		return name, true
+	}
+	name, ok := builtinNames[t]
+	return name, ok
+}
+
+// String returns the name of a built-in item type, e.g., "EOR", or
+// "ItemType(n)" for any other.  Use Record.ItemString to name the item
+// types of a Record.
+func (t ItemType) String() string {
+	if name, ok := builtinNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("ItemType(%d)", int(t))
+}
+
+// String returns a description of the item for debugging, e.g.,
+// EOR(12, "").
+func (i Item) String() string {
+	return fmt.Sprintf("%s(%d, %q)", i.Type, i.Pos, i.Value)
+}
+
+// ItemString returns a description of i for debugging, as Item.String
+// does, but naming its type from the Record's Names, e.g.,
+// RemoteHost(0, "127.0.0.1").
+func (rec Record) ItemString(i Item) string {
+	name, ok := rec.name(i.Type)
+	if !ok {
+		name = i.Type.String()
+	}
+	return fmt.Sprintf("%s(%d, %q)", name, i.Pos, i.Value)
+}
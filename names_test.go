@@ -0,0 +1,62 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestItemTypeString(t *testing.T) {
+	tests := []struct {
+		v      interface{ String() string }
+		expect string
+	}{
+		{ItemEOF, "EOF"},
+		{ItemTruncated, "Truncated"},
+		{ItemType(1001), "ItemType(1001)"},
+		{Item{Type: ItemType(1001), Pos: 12, Value: "a\tb"}, `ItemType(1001)(12, "a\tb")`},
+		{Item{Type: ItemEOR, Pos: 3}, `EOR(3, "")`},
+	}
+	for _, test := range tests {
+		if s := test.v.String(); s != test.expect {
+			t.Errorf("expected %s, got %s", test.expect, s)
+		}
+	}
+}
+
+func TestRecordItemString(t *testing.T) {
+	// two Records may give the same ItemType different names.
+	a := Record{Names: NameMap{ItemA: "Host"}}
+	b := Record{Names: NameMap{ItemA: "Status"}}
+	item := Item{Type: ItemA, Pos: 12, Value: "a\tb"}
+	tests := []struct {
+		rec    Record
+		item   Item
+		expect string
+	}{
+		{a, item, `Host(12, "a\tb")`},
+		{b, item, `Status(12, "a\tb")`},
+		{a, Item{Type: ItemEOR, Pos: 3}, `EOR(3, "")`},
+		{a, Item{Type: ItemB, Pos: 0}, ItemB.String() + `(0, "")`},
+	}
+	for _, test := range tests {
+		if s := test.rec.ItemString(test.item); s != test.expect {
+			t.Errorf("expected %s, got %s", test.expect, s)
+		}
+	}
+}
+
+func TestItemTypeNameInError(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Names:   NameMap{ItemA: "Letters"},
+		States: []Binding{
+			{ItemA, Letters, true}},
+	}
+	items := lexAll(t, "TestItemTypeNameInError", "1\n", rec)
+	if items[0].Type != ItemError || items[0].Err.Binding != "Letters" {
+		t.Errorf("expected an error in the Letters binding, got %v", items[0])
+	}
+	if info := rec.Describe(); info[0].Name != "Letters" {
+		t.Errorf("expected Describe to name the binding Letters, got %q", info[0].Name)
+	}
+}
@@ -0,0 +1,155 @@
+package lexrec
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+)
+
+const hexDigits = "0123456789abcdefABCDEF"
+
+// MAC consumes a hardware address in any of the forms accepted by
+// net.ParseMAC, e.g., "00:1a:2b:3c:4d:5e", "00-1A-2B-3C-4D-5E" or
+// "001a.2b3c.4d5e".
+func MAC(l *Lexer, t ItemType, emit bool) (success bool) {
+	l.AcceptRun(hexDigits + ":-.")
+	if l.Size() == 0 {
+		l.unexpected("MAC address")
+		return false
+	}
+	if l.isAlphaNumeric(l.Peek()) {
+		l.Next()
+		l.Errorf("bad MAC address syntax: %q", l.Bytes())
+		return false
+	}
+	if _, err := net.ParseMAC(string(l.Bytes())); err != nil {
+		l.Errorf("bad MAC address syntax: %q", l.Bytes())
+		return false
+	}
+	if emit {
+		l.Emit(t)
+	} else {
+		l.Skip()
+	}
+	return true
+}
+
+// CIDR consumes an IPv4 or IPv6 address prefix in CIDR notation,
+// e.g., "10.0.0.0/8" or "2001:db8::/32".
+func CIDR(l *Lexer, t ItemType, emit bool) (success bool) {
+	l.AcceptRun(hexDigits + ".:/")
+	if l.Size() == 0 {
+		l.unexpected("CIDR block")
+		return false
+	}
+	if l.isAlphaNumeric(l.Peek()) {
+		l.Next()
+		l.Errorf("bad CIDR syntax: %q", l.Bytes())
+		return false
+	}
+	if _, err := netip.ParsePrefix(string(l.Bytes())); err != nil {
+		l.Errorf("bad CIDR syntax: %q", l.Bytes())
+		return false
+	}
+	if emit {
+		l.Emit(t)
+	} else {
+		l.Skip()
+	}
+	return true
+}
+
+// IPv4 consumes an IPv4 address in dotted-quad form, e.g.,
+// "192.168.0.1".  Octets out of range, e.g., "999.1.1.1", or with
+// leading zeros, which net.ParseIP rejects, are errors.
+func IPv4(l *Lexer, t ItemType, emit bool) (success bool) {
+	l.AcceptRun("0123456789.")
+	if l.Size() == 0 {
+		l.unexpected("IPv4 address")
+		return false
+	}
+	if l.isAlphaNumeric(l.Peek()) {
+		l.Next()
+		l.Errorf("bad IPv4 address syntax: %q", l.Bytes())
+		return false
+	}
+	if addr, err := netip.ParseAddr(string(l.Bytes())); err != nil || !addr.Is4() {
+		l.Errorf("bad IPv4 address syntax: %q", l.Bytes())
+		return false
+	}
+	if emit {
+		l.Emit(t)
+	} else {
+		l.Skip()
+	}
+	return true
+}
+
+// HostField returns a StateFn that consumes the remote host field of
+// an access log: an IPv4 address, an IPv6 address, or a DNS hostname,
+// e.g., "203.0.113.7", "2001:db8::1" or "crawler.example.com".  Which
+// was seen is reported by the item's type: ipv4, ipv6 or hostname, or
+// the Binding's type for any that is ItemError.  A hostname's labels
+// must each hold 1 to 63 letters, digits and hyphens, neither starting
+// nor ending with a hyphen, and its last label must not be all digits,
+// so that a malformed address, e.g., "999.1.1.1", is an error rather
+// than a hostname.
+func HostField(ipv4, ipv6, hostname ItemType) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		l.AcceptRunFunc(func(r rune) bool {
+			return isASCIILetter(r) || isASCIIDigit(r) || strings.ContainsRune(".:-%", r)
+		})
+		if l.Size() == 0 {
+			l.unexpected("host")
+			return false
+		}
+		if l.isAlphaNumeric(l.Peek()) {
+			l.Next()
+			l.Errorf("bad host syntax: %q", l.Bytes())
+			return false
+		}
+		s := string(l.Bytes())
+		var typ ItemType
+		if addr, err := netip.ParseAddr(s); err == nil {
+			typ = ipv6
+			if addr.Is4() {
+				typ = ipv4
+			}
+		} else if validHostname(s) {
+			typ = hostname
+		} else {
+			l.Errorf("bad host syntax: %q", l.Bytes())
+			return false
+		}
+		if typ == ItemError {
+			typ = t
+		}
+		if emit {
+			l.Emit(typ)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// validHostname reports whether s is a DNS hostname, with an optional
+// trailing dot, as described by HostField.
+func validHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	labels := strings.Split(s, ".")
+	for _, label := range labels {
+		if len(label) < 1 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !isASCIILetter(r) && !isASCIIDigit(r) && r != '-' {
+				return false
+			}
+		}
+	}
+	return strings.Trim(labels[len(labels)-1], "0123456789") != ""
+}
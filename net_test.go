@@ -0,0 +1,106 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+// netTest describes the first item expected when lexing input with a
+// Record consisting of a StateFn followed by a newline.
+type netTest struct {
+	input string
+	typ   ItemType
+}
+
+func runNetTests(t *testing.T, name string, fn StateFn, tests []netTest) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemEmit, fn, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	for _, test := range tests {
+		items := lexAll(t, name, test.input, rec)
+		if items[0].Type != test.typ {
+			t.Errorf("%s %q: expected type %d, got %q", name, test.input, test.typ, items[0])
+		} else if test.typ == ItemEmit && items[0].Value != test.input[:len(test.input)-1] {
+			t.Errorf("%s %q: got %q", name, test.input, items[0].Value)
+		}
+	}
+}
+
+func TestMAC(t *testing.T) {
+	runNetTests(t, "MAC", MAC, []netTest{
+		{"00:1a:2b:3c:4d:5e\n", ItemEmit},
+		{"00-1A-2B-3C-4D-5E\n", ItemEmit},
+		{"001a.2b3c.4d5e\n", ItemEmit},
+		{"00:1a:2b:3c:4d\n", ItemError},
+		{"00:1a:2b:3c:4d:5g\n", ItemError},
+	})
+}
+
+func TestCIDR(t *testing.T) {
+	runNetTests(t, "CIDR", CIDR, []netTest{
+		{"10.0.0.0/8\n", ItemEmit},
+		{"2001:db8::/32\n", ItemEmit},
+		{"10.0.0.0/33\n", ItemError},
+		{"10.0.0.0\n", ItemError},
+		{"256.0.0.0/8\n", ItemError},
+	})
+}
+
+func TestIPv4(t *testing.T) {
+	runNetTests(t, "IPv4", IPv4, []netTest{
+		{"192.168.0.1\n", ItemEmit},
+		{"0.0.0.0\n", ItemEmit},
+		{"255.255.255.255\n", ItemEmit},
+		{"999.1.1.1\n", ItemError},
+		{"256.1.1.1\n", ItemError},
+		{"10.0.0\n", ItemError},
+		{"10.0.0.1.2\n", ItemError},
+		{"010.0.0.1\n", ItemError},
+		{"10.0.0.1x\n", ItemError},
+		{"::1\n", ItemError},
+	})
+}
+
+func TestHostField(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, HostField(ItemError, ItemB, ItemAorB), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemIgnore, Letters, false},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	tests := []struct {
+		input string
+		typ   ItemType
+	}{
+		{"203.0.113.7", ItemA},
+		{"2001:db8::1", ItemB},
+		{"::ffff:192.0.2.1", ItemB},
+		{"fe80::1%eth0", ItemB},
+		{"crawler.example.com", ItemAorB},
+		{"localhost", ItemAorB},
+		{"example.com.", ItemAorB},
+		{"a-1.b2", ItemAorB},
+		{"999.1.1.1", ItemError},
+		{"10.0.0", ItemError},
+		{"-bad.example.com", ItemError},
+		{"bad-.example.com", ItemError},
+		{"a..b", ItemError},
+		{strings.Repeat("a", 64) + ".com", ItemError},
+		{"under_score.com", ItemError},
+	}
+	for _, test := range tests {
+		items := lexAll(t, "TestHostField", test.input+" x\n", rec)
+		if items[0].Type != test.typ {
+			t.Errorf("%q: expected type %d, got %v", test.input, test.typ, items[0])
+		} else if test.typ != ItemError && items[0].Value != test.input {
+			t.Errorf("%q: expected the whole host, got %q", test.input, items[0].Value)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package lexrec
+
+import (
+	"fmt"
+	"io"
+)
+
+// NextRecord returns the items of the next record, up to but not
+// including its ItemEOR.  If the record failed to lex, the items
+// emitted before the failure are returned with the Err.Cause of the
+// ItemError, or an error describing the ItemTruncated, that ended
+// it.  Once the input is exhausted NextRecord returns io.EOF, but if
+// the Lexer is canceled or closed first it instead returns the cause
+// of the cancellation, e.g., context.Canceled, from then on.
+func (l *Lexer) NextRecord() ([]Item, error) {
+	items, failed, eof := readRecord(l)
+	if failed {
+		last := items[len(items)-1]
+		items = items[:len(items)-1]
+		if last.Type == ItemTruncated {
+			return items, fmt.Errorf("%s: record truncated at %d: %q", l.name, last.Pos, last.Value)
+		}
+		return items, last.cause()
+	}
+	if eof && len(items) == 0 {
+		return nil, io.EOF
+	}
+	return items, nil
+}
@@ -0,0 +1,85 @@
+package lexrec
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNextRecord(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexer("TestNextRecord", strings.NewReader("ab 1\ncd x\nef 2\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []struct {
+		items string
+		err   bool
+	}{
+		{"ab 1", false},
+		{"cd", true},
+		{"ef 2", false},
+	}
+	for _, e := range expect {
+		items, err := l.NextRecord()
+		values := []string{}
+		for _, item := range items {
+			values = append(values, item.Value)
+		}
+		if got := strings.Join(values, " "); got != e.items || (err != nil) != e.err {
+			t.Errorf("expected %q with error %v, got %q with %v", e.items, e.err, got, err)
+		}
+	}
+	if _, err := l.NextRecord(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	if _, err := l.NextRecord(); err != io.EOF {
+		t.Errorf("expected io.EOF again, got %v", err)
+	}
+}
+
+func TestNextRecordCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := strings.NewReader(strings.Repeat("ab 1\n", 100000))
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexerContext(ctx, "TestNextRecordCanceled", r, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cancel midway through the first record.
+	if item := l.NextItem(); item.Type != ItemA {
+		t.Fatalf("expected an ItemA, got %v", item)
+	}
+	cancel()
+	for {
+		_, err := l.NextRecord()
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		break
+	}
+	if _, err := l.NextRecord(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled again, got %v", err)
+	}
+}
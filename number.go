@@ -0,0 +1,125 @@
+package lexrec
+
+import (
+	"strconv"
+)
+
+// Int returns a StateFn that consumes a base 10 integer with an
+// optional leading sign and verifies that it falls within the range
+// [min, max].  The item is emitted as it appeared in the input.
+func Int(min, max int64) StateFn {
+	return intFn(min, max, false)
+}
+
+// CanonicalInt is like Int but emits the value in canonical form,
+// e.g., "+007" is emitted as "7".
+func CanonicalInt(min, max int64) StateFn {
+	return intFn(min, max, true)
+}
+
+func intFn(min, max int64, canonical bool) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		pos, rpos, width := l.pos, l.rpos, l.width
+		l.Accept("+-")
+		if !acceptDigits(l) {
+			// leave a sign that no digits follow unconsumed.
+			l.pos, l.rpos, l.width = pos, rpos, width
+			l.unexpected("integer")
+			return false
+		}
+		if l.isAlphaNumeric(l.Peek()) {
+			l.Next()
+			l.Errorf("bad integer syntax: %q", l.Bytes())
+			return false
+		}
+		n, err := strconv.ParseInt(string(l.Bytes()), 10, 64)
+		if err != nil || n < min || n > max {
+			l.Errorf("integer %q out of range [%d, %d]", l.Bytes(), min, max)
+			return false
+		}
+		if emit {
+			if canonical {
+				l.EmitValue(t, strconv.FormatInt(n, 10))
+			} else {
+				l.Emit(t)
+			}
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// Float returns a StateFn that consumes a decimal floating point
+// number, with optional sign, fraction, and exponent, and verifies
+// that it falls within the range [min, max].  The item is emitted as
+// it appeared in the input.
+func Float(min, max float64) StateFn {
+	return floatFn(min, max, false)
+}
+
+// CanonicalFloat is like Float but emits the value in the shortest
+// form that round-trips, e.g., "1.50e1" is emitted as "15".
+func CanonicalFloat(min, max float64) StateFn {
+	return floatFn(min, max, true)
+}
+
+func floatFn(min, max float64, canonical bool) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		if !scanDecimal(l) {
+			l.Errorf("bad number syntax: %q", l.Bytes())
+			return false
+		}
+		f, err := strconv.ParseFloat(string(l.Bytes()), 64)
+		if err != nil || f < min || f > max {
+			l.Errorf("number %q out of range [%g, %g]", l.Bytes(), min, max)
+			return false
+		}
+		if emit {
+			if canonical {
+				l.EmitValue(t, strconv.FormatFloat(f, 'g', -1, 64))
+			} else {
+				l.Emit(t)
+			}
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// acceptDigits consumes a run of ASCII digits, returning true if at
+// least one digit was consumed.
+func acceptDigits(l *Lexer) bool {
+	n := l.Size()
+	l.AcceptRun("0123456789")
+	return l.Size() > n
+}
+
+// scanDecimal consumes a decimal floating point number with optional
+// sign, fraction, and exponent, returning false if the syntax is
+// invalid or if the number is immediately followed by an alphanumeric
+// character.
+func scanDecimal(l *Lexer) bool {
+	l.Accept("+-")
+	mantissa := acceptDigits(l)
+	if l.Accept(".") {
+		if acceptDigits(l) {
+			mantissa = true
+		}
+	}
+	if !mantissa {
+		return false
+	}
+	if l.Accept("eE") {
+		l.Accept("+-")
+		if !acceptDigits(l) {
+			return false
+		}
+	}
+	if l.isAlphaNumeric(l.Peek()) {
+		l.Next()
+		return false
+	}
+	return true
+}
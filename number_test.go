@@ -0,0 +1,80 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInt(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemEmit, CanonicalInt(100, 599), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+
+	tests := []struct {
+		input string
+		typ   ItemType
+		value string
+	}{
+		{"200\n", ItemEmit, "200"},
+		{"+0404\n", ItemEmit, "404"},
+		{"600\n", ItemError, ""},
+		{"99\n", ItemError, ""},
+		{"-\n", ItemError, ""},
+		{"20x\n", ItemError, ""},
+	}
+	for _, test := range tests {
+		items := lexAll(t, "TestInt", test.input, rec)
+		if items[0].Type != test.typ {
+			t.Errorf("%q: expected type %d, got %q", test.input, test.typ, items[0])
+		} else if test.typ == ItemEmit && items[0].Value != test.value {
+			t.Errorf("%q: expected %q, got %q", test.input, test.value, items[0].Value)
+		}
+	}
+}
+
+func TestFloat(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemEmit, CanonicalFloat(-90, 90), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+
+	tests := []struct {
+		input string
+		typ   ItemType
+		value string
+	}{
+		{"1.50e1\n", ItemEmit, "15"},
+		{"-.5\n", ItemEmit, "-0.5"},
+		{"90.1\n", ItemError, ""},
+		{"1e\n", ItemError, ""},
+		{".\n", ItemError, ""},
+	}
+	for _, test := range tests {
+		items := lexAll(t, "TestFloat", test.input, rec)
+		if items[0].Type != test.typ {
+			t.Errorf("%q: expected type %d, got %q", test.input, test.typ, items[0])
+		} else if test.typ == ItemEmit && items[0].Value != test.value {
+			t.Errorf("%q: expected %q, got %q", test.input, test.value, items[0].Value)
+		}
+	}
+}
+
+func TestIntSignBackup(t *testing.T) {
+	l, err := NewLexerSync("TestIntSignBackup", strings.NewReader("-x\n"), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Int(-9, 9)(l, ItemEmit, true) {
+		t.Fatalf("expected Int to fail on %q", "-x")
+	}
+	if r := l.Peek(); r != '-' || l.Size() != 0 {
+		t.Errorf("expected the sign to be left unconsumed, got %q after %d bytes", r, l.Size())
+	}
+}
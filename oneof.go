@@ -0,0 +1,30 @@
+package lexrec
+
+// OneOf returns a StateFn that tries each of fns in turn at the
+// current position, rewinding the input between attempts, and lexes
+// the field using the first that succeeds, e.g., for a field that is
+// either a number or a '-':
+//
+//	var digitsOrMinus = lexrec.OneOf(lexrec.Digits, lexrec.Accept("-", true))
+//
+// If all of fns fail, the error of the last is emitted, and the Lexer
+// is left where the OneOf began.
+func OneOf(fns ...StateFn) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		pos, start, rpos, width, eof := l.pos, l.start, l.rpos, l.width, l.eof
+		for _, fn := range fns {
+			l.trial++
+			ok := fn(l, t, false)
+			l.trial--
+			l.pos, l.start, l.rpos, l.width, l.eof = pos, start, rpos, width, eof
+			if ok {
+				return fn(l, t, emit)
+			}
+		}
+		if len(fns) > 0 {
+			l.report(fns[len(fns)-1], t)
+			l.pos, l.start, l.rpos, l.width, l.eof = pos, start, rpos, width, eof
+		}
+		return false
+	}
+}
@@ -0,0 +1,34 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestOneOf(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, OneOf(Digits, Accept("-", true)), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, OneOf(Literal("abc"), Literal("ab")), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestOneOf", "200 abc\n- ab\nx ab\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "200"}, {Type: ItemB, Pos: 4, Value: "abc"}, {Type: ItemEOR, Pos: 8},
+		{Type: ItemA, Pos: 8, Value: "-"}, {Type: ItemB, Pos: 10, Value: "ab"}, {Type: ItemEOR, Pos: 13},
+		{Type: ItemError, Pos: 13},
+		{Type: ItemEOF, Pos: 18}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range items {
+		if items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+	if items[6].Value != `expected character from the set "-", got 'x'` {
+		t.Errorf("expected the error of the last candidate, got %q", items[6].Value)
+	}
+}
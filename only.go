@@ -0,0 +1,15 @@
+package lexrec
+
+// suppressed reports whether items of type t are left out by the
+// Record's Only set.  Built-in item types are never left out.
+func (l *Lexer) suppressed(t ItemType) bool {
+	if len(l.rec.Only) == 0 || t <= ItemEOF {
+		return false
+	}
+	for _, o := range l.rec.Only {
+		if o == t {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+// upper is a StateFn that emits a run of letters in upper case.
+func upper(l *Lexer, t ItemType, emit bool) bool {
+	if !l.AcceptRun("abcdefghijklmnopqrstuvwxyz") {
+		l.Errorf("expected a letter, got %q", l.Peek())
+		return false
+	}
+	l.EmitValue(t, strings.ToUpper(string(l.Bytes())))
+	return true
+}
+
+func TestRecordOnly(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Only:    []ItemType{ItemB},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemAorB, upper, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestRecordOnly", "ab 1 z\ncd x\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemB, Pos: 3, Value: "1"}, {Type: ItemEOR, Pos: 7},
+		{Type: ItemError, Pos: 10},
+		{Type: ItemEOF, Pos: 12}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+}
+
+func TestRecordOnlyLatLonSplit(t *testing.T) {
+	rec := Record{
+		Buflen:  32,
+		ErrorFn: SkipPast("\n"),
+		Only:    []ItemType{ItemB},
+		States: []Binding{
+			{ItemIgnore, LatLonSplit(",", ItemA, ItemB), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestRecordOnlyLatLonSplit", "37.7749,-122.4194\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemB, Pos: 8, Value: "-122.4194"}, {Type: ItemEOR, Pos: 18},
+		{Type: ItemEOF, Pos: 18}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+}
@@ -0,0 +1,60 @@
+package lexrec
+
+import (
+	"sync"
+)
+
+// gate blocks the Lexer's goroutine while it is closed.  The zero
+// value is an open gate.
+type gate struct {
+	mu     sync.Mutex
+	closed chan struct{} // non-nil while the gate is closed
+}
+
+// wait blocks until the gate is open or done is closed.
+func (g *gate) wait(done <-chan struct{}) {
+	g.mu.Lock()
+	ch := g.closed
+	g.mu.Unlock()
+	if ch != nil {
+		select {
+		case <-ch:
+		case <-done:
+		}
+	}
+}
+
+// Pause stops the Lexer from reading any more of its input until
+// Resume is called.  Items lexed from input already read may continue
+// to be delivered.  Pause may be called from any goroutine, and
+// calling it on a paused Lexer has no effect.  A Lexer without a
+// goroutine of its own, e.g., one returned by NewLexerSync, only reads
+// when its caller asks for an item, so Pause has no effect on it.
+func (l *Lexer) Pause() {
+	if l.items == nil {
+		return
+	}
+	l.gate.mu.Lock()
+	if l.gate.closed == nil {
+		l.gate.closed = make(chan struct{})
+	}
+	l.gate.mu.Unlock()
+}
+
+// Resume allows a paused Lexer to continue reading its input.  Calling
+// Resume on a Lexer that is not paused has no effect.
+func (l *Lexer) Resume() {
+	l.gate.mu.Lock()
+	if l.gate.closed != nil {
+		close(l.gate.closed)
+		l.gate.closed = nil
+	}
+	l.gate.mu.Unlock()
+}
+
+// Paused reports whether the Lexer is paused.
+func (l *Lexer) Paused() bool {
+	l.gate.mu.Lock()
+	defer l.gate.mu.Unlock()
+	return l.gate.closed != nil
+}
@@ -0,0 +1,58 @@
+package lexrec
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingReader counts the calls made to Read.
+type countingReader struct {
+	r     io.Reader
+	reads chan struct{}
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	c.reads <- struct{}{}
+	return c.r.Read(p)
+}
+
+func TestLexerPause(t *testing.T) {
+	// construct the Lexer by hand so that it can be paused before
+	// its goroutine starts.
+	r := &countingReader{strings.NewReader("a\na\n"), make(chan struct{}, 100)}
+	l := &Lexer{name: "TestLexerPause", r: r, rec: aRecord, items: make(chan Item), next: make([]byte, 1)}
+	l.Pause()
+	if !l.Paused() {
+		t.Fatal("expected Lexer to be paused")
+	}
+	go l.run()
+	select {
+	case <-r.reads:
+		t.Fatal("read from input while paused")
+	case <-time.After(10 * time.Millisecond):
+	}
+	l.Resume()
+	if l.Paused() {
+		t.Fatal("expected Lexer to be resumed")
+	}
+	if item := l.NextItem(); item.Type != ItemEmit {
+		t.Errorf("expected ItemEmit after Resume, got %q", item)
+	}
+	l.Drain()
+}
+
+func TestLexerSyncPause(t *testing.T) {
+	l, err := NewLexerSync("TestLexerSyncPause", strings.NewReader("a\na\n"), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Pause()
+	if l.Paused() {
+		t.Errorf("expected Pause to have no effect")
+	}
+	if item := l.NextItem(); item.Type != ItemEmit {
+		t.Errorf("expected ItemEmit, got %q", item)
+	}
+}
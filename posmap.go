@@ -0,0 +1,38 @@
+package lexrec
+
+// PosMapper translates positions in the input the Lexer reads into
+// positions in an original source, for input that has passed through
+// a transforming reader, e.g., one that decompresses or transcodes it,
+// so that items and errors can be traced back to the source an
+// operator would look at.
+type PosMapper interface {
+	// MapPos returns the position in the original source of the
+	// byte at pos in the Lexer's input.
+	MapPos(pos int64) int64
+}
+
+// PosMapFunc is a function that implements PosMapper.
+type PosMapFunc func(pos int64) int64
+
+// MapPos returns fn(pos).
+func (fn PosMapFunc) MapPos(pos int64) int64 {
+	return fn(pos)
+}
+
+// mapPos translates the position of item, and of its error's cause,
+// using the Record's PosMap.
+func (l *Lexer) mapPos(item Item) Item {
+	m := l.rec.PosMap
+	item.Pos = m.MapPos(item.Pos)
+	switch err := item.Err.Cause.(type) {
+	case *SyntaxError:
+		err.Pos = m.MapPos(err.Pos)
+	case *UnexpectedRuneError:
+		err.Pos = m.MapPos(err.Pos)
+	case *UnterminatedQuoteError:
+		err.Pos = m.MapPos(err.Pos)
+	case *InvalidEscapeError:
+		err.Pos = m.MapPos(err.Pos)
+	}
+	return item
+}
@@ -0,0 +1,89 @@
+package lexrec
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// latin1Reader transcodes ISO-8859-1 into UTF-8, recording the output
+// position at which each source byte that grew was written.
+type latin1Reader struct {
+	r     io.Reader
+	out   int64
+	grown []int64
+}
+
+func (r *latin1Reader) Read(p []byte) (int, error) {
+	src := make([]byte, len(p)/utf8.UTFMax+1)
+	n, err := r.r.Read(src)
+	k := 0
+	for _, b := range src[:n] {
+		if b >= utf8.RuneSelf {
+			r.grown = append(r.grown, r.out+int64(k))
+		}
+		k += utf8.EncodeRune(p[k:], rune(b))
+	}
+	r.out += int64(k)
+	return k, err
+}
+
+// MapPos subtracts the extra byte written for each source byte that
+// grew before pos.
+func (r *latin1Reader) MapPos(pos int64) int64 {
+	src := pos
+	for _, g := range r.grown {
+		if g < pos {
+			src--
+		}
+	}
+	return src
+}
+
+func TestPosMap(t *testing.T) {
+	r := &latin1Reader{r: strings.NewReader("caf\xe9 ab\n\xe9t\xe9 1\n\xe0 cd\n")}
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		PosMap:  r,
+		States: []Binding{
+			{ItemA, ExceptRun(" ", true), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexerSync("TestPosMap", r, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []Item
+	for l.Scan() {
+		items = append(items, l.Item())
+	}
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "café"}, {Type: ItemB, Pos: 5, Value: "ab"}, {Type: ItemEOR, Pos: 8},
+		{Type: ItemA, Pos: 8, Value: "été"}, {Type: ItemError, Pos: 12},
+		{Type: ItemA, Pos: 14, Value: "à"}, {Type: ItemB, Pos: 16, Value: "cd"}, {Type: ItemEOR, Pos: 19},
+		{Type: ItemEOF, Pos: 19}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range items {
+		if items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+	var ure *UnexpectedRuneError
+	if !errors.As(items[4].Err.Cause, &ure) || ure.Pos != 12 {
+		t.Errorf("expected an *UnexpectedRuneError at 12, got %v", items[4].Err.Cause)
+	}
+}
+
+func TestPosMapFunc(t *testing.T) {
+	var m PosMapper = PosMapFunc(func(pos int64) int64 { return pos * 2 })
+	if got := m.MapPos(21); got != 42 {
+		t.Errorf("expected 42, got %d", got)
+	}
+}
@@ -0,0 +1,76 @@
+package lexrec
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HexInt consumes a base 16 integer with a 0x or 0X prefix, e.g., a
+// memory address such as 0x7ffd5e8c.  The digits must fit in a uint64.
+// A bare prefix, with no digits after it, is an error, and nothing is
+// consumed.  The item is emitted as it appeared in the input, prefix
+// included.
+func HexInt(l *Lexer, t ItemType, emit bool) (success bool) {
+	return prefixedInt(l, t, emit, "xX")
+}
+
+// OctalInt is like HexInt but consumes a base 8 integer with a 0o or
+// 0O prefix.
+func OctalInt(l *Lexer, t ItemType, emit bool) (success bool) {
+	return prefixedInt(l, t, emit, "oO")
+}
+
+// BinaryInt is like HexInt but consumes a base 2 integer with a 0b or
+// 0B prefix, e.g., a flag field such as 0b1010.
+func BinaryInt(l *Lexer, t ItemType, emit bool) (success bool) {
+	return prefixedInt(l, t, emit, "bB")
+}
+
+// PrefixedInt is like HexInt but consumes an integer with any of the
+// prefixes accepted by HexInt, OctalInt and BinaryInt, its base
+// detected from the prefix.
+func PrefixedInt(l *Lexer, t ItemType, emit bool) (success bool) {
+	return prefixedInt(l, t, emit, "xXoObB")
+}
+
+// prefixedInt consumes an integer whose prefix is '0' followed by one
+// of the runes in prefixes.
+func prefixedInt(l *Lexer, t ItemType, emit bool, prefixes string) bool {
+	pos, rpos, width, eof := l.pos, l.rpos, l.width, l.eof
+	r := l.Next()
+	if r == '0' {
+		r = l.Next()
+	}
+	if !strings.ContainsRune(prefixes, r) || l.Size() != 2 {
+		l.pos, l.rpos, l.width, l.eof = pos, rpos, width, eof
+		l.unexpected("an integer prefixed with 0" + strings.Join(strings.Split(prefixes, ""), ", 0"))
+		return false
+	}
+	base, digits := 16, "0123456789abcdefABCDEF"
+	switch r {
+	case 'o', 'O':
+		base, digits = 8, "01234567"
+	case 'b', 'B':
+		base, digits = 2, "01"
+	}
+	if !l.AcceptRun(digits) {
+		l.Errorf("bare %q prefix with no digits", l.Bytes())
+		l.pos, l.rpos, l.width, l.eof = pos, rpos, width, eof
+		return false
+	}
+	if l.isAlphaNumeric(l.Peek()) {
+		l.Next()
+		l.Errorf("bad integer syntax: %q", l.Bytes())
+		return false
+	}
+	if _, err := strconv.ParseUint(string(l.Bytes()[2:]), base, 64); err != nil {
+		l.Errorf("integer %q overflows uint64", l.Bytes())
+		return false
+	}
+	if emit {
+		l.Emit(t)
+	} else {
+		l.Skip()
+	}
+	return true
+}
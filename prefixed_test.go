@@ -0,0 +1,70 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestPrefixedInt(t *testing.T) {
+	tests := []struct {
+		fn    StateFn
+		input string
+		typ   ItemType
+		value string
+	}{
+		{HexInt, "0x7ffd5e8c\n", ItemEmit, "0x7ffd5e8c"},
+		{HexInt, "0XFFFFFFFFFFFFFFFF\n", ItemEmit, "0XFFFFFFFFFFFFFFFF"},
+		{HexInt, "0x10000000000000000\n", ItemError, ""},
+		{HexInt, "0x\n", ItemError, ""},
+		{HexInt, "0xg\n", ItemError, ""},
+		{HexInt, "0x1g\n", ItemError, ""},
+		{HexInt, "0o7\n", ItemError, ""},
+		{HexInt, "x1\n", ItemError, ""},
+		{HexInt, "\n", ItemError, ""},
+		{OctalInt, "0o755\n", ItemEmit, "0o755"},
+		{OctalInt, "0o8\n", ItemError, ""},
+		{BinaryInt, "0b1010\n", ItemEmit, "0b1010"},
+		{BinaryInt, "0B12\n", ItemError, ""},
+		{PrefixedInt, "0xff\n", ItemEmit, "0xff"},
+		{PrefixedInt, "0o17\n", ItemEmit, "0o17"},
+		{PrefixedInt, "0b1\n", ItemEmit, "0b1"},
+		{PrefixedInt, "017\n", ItemError, ""},
+	}
+	for _, test := range tests {
+		rec := Record{
+			Buflen:  16,
+			ErrorFn: SkipPast("\n"),
+			States: []Binding{
+				{ItemEmit, test.fn, true},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		items := lexAll(t, "TestPrefixedInt", test.input, rec)
+		if items[0].Type != test.typ {
+			t.Errorf("%q: expected type %d, got %q", test.input, test.typ, items[0])
+		} else if test.typ == ItemEmit && items[0].Value != test.value {
+			t.Errorf("%q: expected %q, got %q", test.input, test.value, items[0].Value)
+		}
+	}
+}
+
+func TestPrefixedIntBarePrefix(t *testing.T) {
+	// on a bare prefix nothing is consumed, so another StateFn may
+	// be tried in its place.
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, OneOf(HexInt, AcceptRun("0x", true)), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestPrefixedIntBarePrefix", "0x\n0x1f\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: "0x"}, {Type: ItemEOR, Pos: 3},
+		{Type: ItemA, Pos: 3, Value: "0x1f"}, {Type: ItemEOR, Pos: 8},
+		{Type: ItemEOF, Pos: 8}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+	if err := CheckStateFn(PrefixedInt, GenChoice("0x", "0x1f", "0o7", "0b", "0b101"), 20, 1); err != nil {
+		t.Errorf("unexpected violation: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package lexrec
+
+import (
+	"fmt"
+	"io"
+)
+
+// NewLexerSync returns a lexer for rec records from the UTF-8 reader
+// r that runs in the caller's goroutine rather than in a goroutine of
+// its own.  Items are read with Scan and Item, or NextItem, each call
+// lexing as much of the input as is needed to return the next item,
+// without the cost of a channel send per item.  Since nothing runs
+// between calls, the caller may stop at any time without calling
+// Close.  The name is only used for debugging messages.
+func NewLexerSync(name string, r io.Reader, rec Record) (l *Lexer, err error) {
+	if len(rec.States) == 0 {
+		err = fmt.Errorf("rec.states must not be empty.")
+		return
+	}
+	if rec.Buflen < 1 {
+		err = fmt.Errorf("rec.Buflen must be > 0: %d", rec.Buflen)
+		return
+	}
+	if rec.ErrorFn == nil {
+		err = fmt.Errorf("rec.ErrorFn must not be nil")
+		return
+	}
+	l = &Lexer{
+		name: name,
+		r:    r,
+		rec:  rec,
+		next: make([]byte, rec.Buflen),
+	}
+	l.out = func(item Item) {
+		l.queue = append(l.queue, item)
+	}
+	return
+}
+
+// Scan advances a Lexer returned by NewLexerSync to its next item,
+// which is then available from Item.  Scan returns false once the
+// ItemEOF has been returned, or at once for a Lexer whose goroutine
+// is lexing the input, e.g., one returned by NewLexer, whose items
+// must be read with NextItem instead, e.g.:
+//
+//	for l.Scan() {
+//		item := l.Item()
+//		...
+//	}
+func (l *Lexer) Scan() bool {
+	if l.items != nil {
+		return false
+	}
+	if l.canceled() {
+		l.ended, l.queue, l.head = true, nil, 0
+		return false
+	}
+	for l.head == len(l.queue) {
+		if l.ended {
+			return false
+		}
+		l.queue, l.head = l.queue[:0], 0
+		l.ended = !l.record()
+	}
+	l.item = l.queue[l.head]
+	l.head++
+	l.lastPos = l.item.Pos
+	return true
+}
+
+// Item returns the item most recently read by Scan.
+func (l *Lexer) Item() Item {
+	return l.item
+}
@@ -0,0 +1,96 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewLexerSync(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	input := "ab\nc1\nde\n"
+	expect := lexAll(t, "TestNewLexerSync", input, rec)
+
+	l, err := NewLexerSync("TestNewLexerSync", strings.NewReader(input), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []Item
+	for l.Scan() {
+		items = append(items, l.Item())
+	}
+	if len(items) != len(expect) {
+		t.Fatalf("expected %v, got %v", expect, items)
+	}
+	for i := range items {
+		items[i].Err.Cause, expect[i].Err.Cause = nil, nil
+		if !sameItem(items[i], expect[i]) {
+			t.Errorf("expected %v, got %v", expect[i], items[i])
+		}
+	}
+	if l.Scan() {
+		t.Errorf("expected Scan to return false after ItemEOF")
+	}
+}
+
+func TestNewLexerSyncClose(t *testing.T) {
+	l, err := NewLexerSync("TestNewLexerSyncClose", strings.NewReader("a\na\n"), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item := l.NextItem(); item.Type != ItemEmit {
+		t.Errorf("expected an ItemEmit, got %v", item)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if l.Scan() {
+		t.Errorf("expected Scan to return false after Close")
+	}
+}
+
+func BenchmarkLexerSync(b *testing.B) {
+	input := strings.Repeat("abc\n", 1000)
+	rec := Record{
+		Buflen:  4096,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	for i := 0; i < b.N; i++ {
+		l, err := NewLexerSync("BenchmarkLexerSync", strings.NewReader(input), rec)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for l.Scan() {
+		}
+	}
+}
+
+func TestScanAsync(t *testing.T) {
+	l, err := NewLexer("TestScanAsync", strings.NewReader("a\na\n"), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Synchronous {
+		l.Drain()
+		t.Skip("every Lexer runs in the caller's goroutine")
+	}
+	if l.Scan() {
+		t.Errorf("expected Scan to return false for a Lexer with a goroutine")
+	}
+	b := []byte{}
+	if l.ScanRecord(Buffers{ItemEmit: &b}) {
+		t.Errorf("expected ScanRecord to return false for a Lexer with a goroutine")
+	}
+	if item := l.NextItem(); item.Type != ItemEmit {
+		t.Errorf("expected an ItemEmit, got %v", item)
+	}
+	l.Drain()
+}
@@ -0,0 +1,120 @@
+package lexrec
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// RuneSet is a set of runes compiled from a compact range notation,
+// e.g., "a-zA-Z0-9_", for testing membership in constant time for
+// ASCII runes and logarithmic time for the rest, rather than scanning
+// a long literal set string for every rune.
+type RuneSet struct {
+	ascii  [2]uint64 // bit r set if ASCII rune r is in the set
+	ranges [][2]rune // sorted, non-overlapping intervals of non-ASCII runes
+	spec   string    // the notation the set was compiled from
+}
+
+// ParseRuneSet compiles spec, a sequence of single runes and intervals
+// lo-hi, into a RuneSet.  A '-' that is first or last in spec stands
+// for itself.  An error is returned if an interval's hi is below its
+// lo, or spec is not valid UTF-8.
+func ParseRuneSet(spec string) (*RuneSet, error) {
+	if !utf8.ValidString(spec) {
+		return nil, fmt.Errorf("rune set %q is not valid UTF-8", spec)
+	}
+	s := &RuneSet{spec: spec}
+	runes := []rune(spec)
+	for i := 0; i < len(runes); i++ {
+		lo, hi := runes[i], runes[i]
+		if i+2 < len(runes) && runes[i+1] == '-' {
+			hi = runes[i+2]
+			i += 2
+		}
+		if hi < lo {
+			return nil, fmt.Errorf("rune set %q has an invalid range %q-%q", spec, lo, hi)
+		}
+		s.add(lo, hi)
+	}
+	return s, nil
+}
+
+// MustRuneSet is like ParseRuneSet but panics if spec is invalid.  It
+// simplifies the initialization of variables holding RuneSets.
+func MustRuneSet(spec string) *RuneSet {
+	s, err := ParseRuneSet(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// add adds the interval lo-hi to the set.
+func (s *RuneSet) add(lo, hi rune) {
+	for ; lo <= hi && lo < utf8.RuneSelf; lo++ {
+		s.ascii[lo/64] |= 1 << (lo % 64)
+	}
+	if lo > hi {
+		return
+	}
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i][1] >= lo-1 })
+	j := i
+	for j < len(s.ranges) && s.ranges[j][0] <= hi+1 {
+		lo, hi = min(lo, s.ranges[j][0]), max(hi, s.ranges[j][1])
+		j++
+	}
+	s.ranges = append(s.ranges[:i], append([][2]rune{{lo, hi}}, s.ranges[j:]...)...)
+}
+
+// Contains reports whether r is in the set.
+func (s *RuneSet) Contains(r rune) bool {
+	if r < 0 {
+		return false
+	}
+	if r < utf8.RuneSelf {
+		return s.ascii[r/64]&(1<<(r%64)) != 0
+	}
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i][1] >= r })
+	return i < len(s.ranges) && s.ranges[i][0] <= r
+}
+
+// String returns the notation the set was compiled from.
+func (s *RuneSet) String() string {
+	return s.spec
+}
+
+// AcceptRanges returns a StateFn that consumes one rune from the set
+// given in range notation by spec, as described by ParseRuneSet, e.g.,
+// AcceptRanges("a-f0-9", true).  If needed is true and no rune is
+// consumed, an error is emitted.  AcceptRanges panics if spec is
+// invalid.
+func AcceptRanges(spec string, needed bool) StateFn {
+	s := MustRuneSet(spec)
+	return funcState(func(l *Lexer) bool { return l.AcceptFunc(s.Contains) }, needed,
+		fmt.Sprintf("a character from the ranges %q", spec))
+}
+
+// ExceptRanges is like AcceptRanges, but consumes one rune that is
+// not in the set.
+func ExceptRanges(spec string, needed bool) StateFn {
+	s := MustRuneSet(spec)
+	return funcState(func(l *Lexer) bool { return l.ExceptFunc(s.Contains) }, needed,
+		fmt.Sprintf("a character outside the ranges %q", spec))
+}
+
+// AcceptRunRanges is like AcceptRanges, but consumes a run of runes
+// from the set.
+func AcceptRunRanges(spec string, needed bool) StateFn {
+	s := MustRuneSet(spec)
+	return funcState(func(l *Lexer) bool { return l.AcceptRunFunc(s.Contains) }, needed,
+		fmt.Sprintf("a run of characters from the ranges %q", spec))
+}
+
+// ExceptRunRanges is like AcceptRanges, but consumes a run of runes
+// that are not in the set.
+func ExceptRunRanges(spec string, needed bool) StateFn {
+	s := MustRuneSet(spec)
+	return funcState(func(l *Lexer) bool { return l.ExceptRunFunc(s.Contains) }, needed,
+		fmt.Sprintf("a run of characters outside the ranges %q", spec))
+}
@@ -0,0 +1,66 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestRuneSet(t *testing.T) {
+	s, err := ParseRuneSet("-a-fx0-9_α-γ€ά-έ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range "abcfx059_-αβγ€άέ" {
+		if !s.Contains(r) {
+			t.Errorf("expected %q in %s", r, s)
+		}
+	}
+	if s.Contains(EOF) {
+		t.Errorf("expected EOF not in %s", s)
+	}
+	for _, r := range "gwA:δ£ή" {
+		if s.Contains(r) {
+			t.Errorf("expected %q not in %s", r, s)
+		}
+	}
+	if len(s.ranges) != 3 {
+		t.Errorf("expected 3 non-ASCII ranges, got %v", s.ranges)
+	}
+	for _, spec := range []string{"z-a", "a-\xff"} {
+		if _, err := ParseRuneSet(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestRuneSetMerge(t *testing.T) {
+	s := MustRuneSet("ω-ϙ")
+	s.add('α', 'γ')
+	s.add('δ', 'ε')
+	s.add('ϐ', 'Ϟ')
+	s.add('β', 'ζ')
+	want := [][2]rune{{'α', 'ζ'}, {'ω', 'Ϟ'}}
+	if len(s.ranges) != len(want) || s.ranges[0] != want[0] || s.ranges[1] != want[1] {
+		t.Errorf("expected %q, got %q", want, s.ranges)
+	}
+}
+
+func TestAcceptRanges(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, AcceptRunRanges("a-f0-9", true), true},
+			{ItemIgnore, AcceptRanges(" :", true), false},
+			{ItemB, ExceptRunRanges("\n\t", true), true},
+			{ItemIgnore, ExceptRanges("a-z", true), false}},
+	}
+	items := lexAll(t, "TestAcceptRanges", "dead01 g h\nbeef:xyz\nxyz ab\n", rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: "dead01"}, {Type: ItemB, Pos: 7, Value: "g h"}, {Type: ItemEOR, Pos: 11},
+		{Type: ItemA, Pos: 11, Value: "beef"}, {Type: ItemB, Pos: 16, Value: "xyz"}, {Type: ItemEOR, Pos: 20},
+		{Type: ItemError, Pos: 20},
+		{Type: ItemEOF, Pos: 27}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+}
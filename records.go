@@ -0,0 +1,34 @@
+package lexrec
+
+// readRecord reads the items of the next record from l, up to but not
+// including its ItemEOR.  If the record failed to lex, failed is true
+// and items holds those emitted before the ItemError or ItemTruncated,
+// followed by the ItemError or ItemTruncated itself.  If the input
+// is exhausted eof is true and items holds any items emitted before
+// the ItemEOF.  If the Lexer was canceled or closed, failed and eof
+// are both true, and items ends with the ItemError reporting the
+// cancellation.
+// ItemRecovered, ItemAnomaly and ItemWarning items, which describe
+// the input rather than hold fields of the record, are discarded.
+func readRecord(l *Lexer) (items []Item, failed bool, eof bool) {
+	for {
+		item, ok := l.nextItem()
+		if !ok {
+			if l.canceled() {
+				return append(items, item), true, true
+			}
+			return items, false, true
+		}
+		switch item.Type {
+		case ItemEOR:
+			return items, false, false
+		case ItemError, ItemTruncated:
+			return append(items, item), true, false
+		case ItemEOF:
+			return items, false, true
+		case ItemRecovered, ItemAnomaly, ItemWarning:
+			continue
+		}
+		items = append(items, item)
+	}
+}
@@ -0,0 +1,101 @@
+package lexrec
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Regexp returns a StateFn that lexes the whole of the current record,
+// up to but not including its terminator, by matching it against re.
+// The terminator is the Record's Terminator or, if it has none, a
+// newline.  The record must match re in full.  Each named group of re
+// whose name appears in types is emitted as an item of the
+// corresponding type, in the order in which the groups appear in re;
+// groups that did not participate in the match are not emitted.  If
+// emit is true, the record is first emitted in full as an item of
+// type t.
+func Regexp(re *regexp.Regexp, types NameMap) StateFn {
+	type group struct {
+		index int
+		t     ItemType
+	}
+	groups := []group{}
+	for t, name := range types {
+		if i := re.SubexpIndex(name); i > 0 {
+			groups = append(groups, group{i, t})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].index < groups[j].index
+	})
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		l.toTerminator()
+		record := l.buf[l.start:l.pos]
+		m := re.FindSubmatchIndex(record)
+		if m == nil || m[0] != 0 || m[1] != len(record) {
+			l.Errorf("record does not match %s: %q", re, record)
+			return false
+		}
+		pos := l.tokenPos()
+		if emit {
+			l.emit(Item{Type: t, Pos: pos, Value: string(record)})
+		}
+		for _, g := range groups {
+			from, to := m[2*g.index], m[2*g.index+1]
+			if from >= 0 {
+				l.emit(Item{Type: g.t, Pos: pos + int64(from), Value: string(record[from:to])})
+			}
+		}
+		l.Skip()
+		return true
+	}
+}
+
+// RegexpRecord returns a Record of newline-terminated records lexed
+// by matching each against re, emitting its named groups as
+// described by Regexp.  The types are also used as the Record's
+// Names.  The Record's single Binding has type t, which is used to
+// report errors but is not itself emitted.
+func RegexpRecord(re *regexp.Regexp, t ItemType, types NameMap) Record {
+	return Record{
+		Buflen:     4096,
+		ErrorFn:    SkipRecord,
+		Names:      types,
+		Terminator: newline,
+		States: []Binding{
+			{t, Regexp(re, types), false}},
+	}
+}
+
+// MatchRegexp returns a StateFn that consumes the prefix of the input
+// at the current position matched by re, as if re began with ^,
+// extending the read buffer as needed.  An empty match fails.  This suits
+// fields too irregular to express with sets of characters.  The input
+// is read as far as re needs to decide on a match, which for an
+// unbounded pattern such as `(?s).*` is the rest of the input.  If
+// needed is true and re does not match, an error is emitted.
+func MatchRegexp(re *regexp.Regexp, needed bool) StateFn {
+	anchored := regexp.MustCompile(`^(?:` + re.String() + `)`)
+	expected := fmt.Sprintf("a match for %s", re)
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		m := l.Mark()
+		loc := anchored.FindReaderIndex(l.Input())
+		l.Rewind(m)
+		if loc == nil || loc[1] == 0 {
+			if needed {
+				l.unexpected(expected)
+			}
+			return false
+		}
+		l.pos += loc[1]
+		l.rpos += int64(loc[1])
+		l.width = 0
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
@@ -0,0 +1,52 @@
+package lexrec
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRegexpRecord(t *testing.T) {
+	re := regexp.MustCompile(`(?P<host>\S+) (?P<status>\d+)( (?P<bytes>\d+))?`)
+	rec := RegexpRecord(re, ItemIgnore, NameMap{ItemA: "host", ItemB: "status", ItemAorB: "bytes"})
+	items := lexAll(t, "TestRegexpRecord", "a.b 200 12\nbad\nc 404\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "a.b"}, {Type: ItemB, Pos: 4, Value: "200"}, {Type: ItemAorB, Pos: 8, Value: "12"}, {Type: ItemEOR, Pos: 11},
+		{Type: ItemError, Pos: 14},
+		{Type: ItemA, Pos: 15, Value: "c"}, {Type: ItemB, Pos: 17, Value: "404"}, {Type: ItemEOR, Pos: 21},
+		{Type: ItemEOF, Pos: 21}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range items {
+		if items[i].Type != ItemError && items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+}
+
+func TestMatchRegexp(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, MatchRegexp(regexp.MustCompile(`[a-z]+(-[a-z]+)*`), true), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, MatchRegexp(regexp.MustCompile(`\d{4}-\d{2}-\d{2}|-`), true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestMatchRegexp", "x-ray-tango 2024-01-31\nab -\n9 -\nab 2024-1\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "x-ray-tango"}, {Type: ItemB, Pos: 12, Value: "2024-01-31"}, {Type: ItemEOR, Pos: 23},
+		{Type: ItemA, Pos: 23, Value: "ab"}, {Type: ItemB, Pos: 26, Value: "-"}, {Type: ItemEOR, Pos: 28},
+		{Type: ItemError, Pos: 28},
+		{Type: ItemA, Pos: 32, Value: "ab"}, {Type: ItemError, Pos: 35},
+		{Type: ItemEOF, Pos: 42}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range items {
+		if items[i].Type != ItemError && items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package lexrec
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry holds named CompiledRecords for a long-running service, so
+// that formats can be looked up by name, and added or updated without
+// redeploying the code that parses them.  A Registry is safe for
+// concurrent use.  Lexers already running on a Record that is
+// replaced carry on with the Record they were created with.
+type Registry struct {
+	mu      sync.RWMutex
+	records map[string]registered
+}
+
+// registered is a CompiledRecord and the source that registered it.
+type registered struct {
+	rec    *CompiledRecord
+	source string
+}
+
+// DefaultRegistry is the process-wide Registry.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{records: make(map[string]registered)}
+}
+
+// Register compiles rec and registers it as name, replacing any Record
+// already registered as name by code.  It returns an error if rec
+// does not compile, or if name was registered by Reload.
+func (r *Registry) Register(name string, rec Record) error {
+	c, err := Compile(rec)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.records[name]; ok && old.source != "" {
+		return fmt.Errorf("%s: already registered by %s", name, old.source)
+	}
+	r.records[name] = registered{c, ""}
+	return nil
+}
+
+// Unregister removes the Record registered as name, if any.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, name)
+}
+
+// Lookup returns the Record registered as name.
+func (r *Registry) Lookup(name string) (*CompiledRecord, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reg, ok := r.records[name]
+	return reg.rec, ok
+}
+
+// Names returns the names of the registered Records, in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.records))
+	for name := range r.records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Reload replaces the Records registered by source, such as the path
+// of a configuration file, with recs: Records missing from recs are
+// unregistered, and the others are added or replaced.  Every Record
+// is compiled before any is registered, so that if one of them fails
+// to compile, or has a name registered by code or by another source,
+// Reload returns an error and the Registry is left unchanged.
+func (r *Registry) Reload(source string, recs map[string]Record) error {
+	if source == "" {
+		return fmt.Errorf("source must not be empty")
+	}
+	compiled := make(map[string]*CompiledRecord, len(recs))
+	for name, rec := range recs {
+		c, err := Compile(rec)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %v", source, name, err)
+		}
+		compiled[name] = c
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range compiled {
+		if old, ok := r.records[name]; ok && old.source != source {
+			if old.source == "" {
+				return fmt.Errorf("%s: %s: already registered by code", source, name)
+			}
+			return fmt.Errorf("%s: %s: already registered by %s", source, name, old.source)
+		}
+	}
+	for name, reg := range r.records {
+		if _, ok := compiled[name]; !ok && reg.source == source {
+			delete(r.records, name)
+		}
+	}
+	for name, c := range compiled {
+		r.records[name] = registered{c, source}
+	}
+	return nil
+}
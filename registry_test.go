@@ -0,0 +1,67 @@
+package lexrec
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	a := Record{Buflen: 16, ErrorFn: SkipPast("\n"), States: aRecord.States}
+	if err := r.Register("a", a); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("bad", Record{Buflen: 16, ErrorFn: SkipPast("\n"), States: aRecord.States, ZeroCopy: true}); err == nil {
+		t.Errorf("expected an error registering a ZeroCopy Record")
+	}
+	if err := r.Reload("formats.conf", map[string]Record{"b": a, "c": a}); err != nil {
+		t.Fatal(err)
+	}
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"a", "b", "c"}) {
+		t.Errorf("expected [a b c], got %v", names)
+	}
+	if err := r.Reload("formats.conf", map[string]Record{"a": a}); err == nil {
+		t.Errorf("expected an error reloading a name registered by code")
+	}
+	if err := r.Register("b", a); err == nil {
+		t.Errorf("expected an error registering a name registered by Reload")
+	}
+	if err := r.Reload("formats.conf", map[string]Record{"c": a, "d": a, "e": {}}); err == nil {
+		t.Errorf("expected an error reloading a Record that does not compile")
+	}
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"a", "b", "c"}) {
+		t.Errorf("expected a failed Reload to leave [a b c], got %v", names)
+	}
+	if err := r.Reload("formats.conf", map[string]Record{"c": a, "d": a}); err != nil {
+		t.Fatal(err)
+	}
+	r.Unregister("a")
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"c", "d"}) {
+		t.Errorf("expected [c d], got %v", names)
+	}
+	if _, ok := r.Lookup("b"); ok {
+		t.Errorf("expected b to have been unregistered by Reload")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Reload("formats.conf", map[string]Record{"c": a, "d": a})
+			c, ok := r.Lookup("c")
+			if !ok {
+				t.Errorf("expected c to be registered")
+				return
+			}
+			l := c.NewLexer("TestRegistry", strings.NewReader("aaa"))
+			if item := l.NextItem(); item.Value != "aaa" {
+				t.Errorf("expected aaa, got %v", item)
+			}
+			l.Drain()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,37 @@
+package lexrec
+
+import (
+	"io"
+	"text/template"
+)
+
+// RenderRecords reads records from l until ItemEOF, executing tmpl
+// once per successfully lexed record and writing the result to w.  The
+// template is passed a map of the record's item values keyed by the
+// names in the Record's Names, so that a field named "Host" may be
+// referenced as {{.Host}}.  Items whose type has no name are omitted,
+// and if an item type repeats within a record the last value is used.
+// Records that fail to lex are skipped.  RenderRecords returns the
+// first error returned by the template.
+func RenderRecords(l *Lexer, tmpl *template.Template, w io.Writer) error {
+	fields := make(map[string]string)
+	for {
+		items, failed, eof := readRecord(l)
+		if !failed && len(items) > 0 {
+			for k := range fields {
+				delete(fields, k)
+			}
+			for _, item := range items {
+				if name, ok := l.rec.Names[item.Type]; ok {
+					fields[name] = item.Value
+				}
+			}
+			if err := tmpl.Execute(w, fields); err != nil {
+				return err
+			}
+		}
+		if eof {
+			return nil
+		}
+	}
+}
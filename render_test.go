@@ -0,0 +1,33 @@
+package lexrec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRenderRecords(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Names:   NameMap{ItemA: "Name", ItemB: "Count"},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexer("TestRenderRecords", strings.NewReader("ab 12\n! 1\nc 3\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := template.Must(template.New("test").Parse("{{.Count}}={{.Name}};"))
+	buf := new(bytes.Buffer)
+	if err := RenderRecords(l, tmpl, buf); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "12=ab;3=c;"; buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+}
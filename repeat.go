@@ -0,0 +1,51 @@
+package lexrec
+
+// Repeat returns a StateFn that applies fn between min and max times,
+// as many times as it succeeds, and emits the combined span as a
+// single item, e.g., Repeat(2, 2, Accept("0123456789", true)) for
+// exactly two digits.  fn is applied as if being tried, so that the
+// application that ends the repetition emits nothing.  If fn succeeds
+// fewer than min times, the error of the application that failed is
+// emitted and nothing is consumed.  A max less than 1 means no limit.
+func Repeat(min, max int, fn StateFn) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		pos, start, rpos, width, eof := l.pos, l.start, l.rpos, l.width, l.eof
+		n := 0
+		l.trial++
+		for max < 1 || n < max {
+			p, r, w, e := l.pos, l.rpos, l.width, l.eof
+			if !fn(l, t, false) {
+				l.pos, l.rpos, l.width, l.eof = p, r, w, e
+				break
+			}
+			n++
+			if l.pos == p {
+				// fn consumed nothing, and would do so forever.
+				break
+			}
+		}
+		l.trial--
+		if n < min {
+			l.report(fn, t)
+			l.pos, l.start, l.rpos, l.width, l.eof = pos, start, rpos, width, eof
+			return false
+		}
+		l.start = start
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// report runs fn, which failed when tried at the current position, so
+// that it emits its error.  The buffer is held while it runs, so that
+// positions saved by the caller remain valid.
+func (l *Lexer) report(fn StateFn, t ItemType) {
+	keep := l.keep
+	l.keep = true
+	fn(l, t, false)
+	l.keep = keep
+}
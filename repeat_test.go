@@ -0,0 +1,41 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestRepeat(t *testing.T) {
+	segment := func(l *Lexer, t ItemType, emit bool) bool {
+		if !l.Accept("/") || !l.AcceptRun("abcdefghijklmnopqrstuvwxyz") {
+			l.Errorf("expected a path segment, got %q", l.Peek())
+			return false
+		}
+		l.Skip()
+		return true
+	}
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Repeat(2, 2, Accept("0123456789", true)), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Repeat(1, 3, segment), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestRepeat", "12 /a/bc\n1 /a\n12 /a/b/c/d\n12 x\n34 /abc\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "12"}, {Type: ItemB, Pos: 3, Value: "/a/bc"}, {Type: ItemEOR, Pos: 9},
+		{Type: ItemError, Pos: 10},
+		{Type: ItemA, Pos: 14, Value: "12"}, {Type: ItemB, Pos: 17, Value: "/a/b/c"}, {Type: ItemError, Pos: 23},
+		{Type: ItemA, Pos: 26, Value: "12"}, {Type: ItemError, Pos: 29},
+		{Type: ItemA, Pos: 31, Value: "34"}, {Type: ItemB, Pos: 34, Value: "/abc"}, {Type: ItemEOR, Pos: 39},
+		{Type: ItemEOF, Pos: 39}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range items {
+		if items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package lexrec
+
+import (
+	"context"
+	"io"
+)
+
+// Reset stops the Lexer, as Close does, and prepares it to lex the
+// UTF-8 reader r from the start, with the same Record, reusing its
+// buffers rather than allocating new ones.  This saves allocations
+// when lexing many small inputs.  A Lexer returned by NewLexer or
+// NewLexerContext starts a new goroutine, under the same context,
+// while one returned by NewLexerSync continues to run in the caller's
+// goroutine.  Reset must not be used with a Lexer returned by
+// NewLexerRun.  The name is only used for debugging messages.
+func (l *Lexer) Reset(name string, r io.Reader) {
+	async := l.items != nil || l.parent != nil
+	if async {
+		l.Close()
+	}
+	*l = Lexer{
+		name:    name,
+		r:       r,
+		rec:     l.rec,
+		next:    l.next,
+		buf:     l.buf[:0],
+		queue:   l.queue[:0],
+		scratch: l.scratch,
+		out:     l.out,
+		parent:  l.parent,
+	}
+	if async {
+		if l.parent == nil {
+			l.parent = context.Background()
+		}
+		l.ctx, l.stop = context.WithCancel(l.parent)
+		l.spawn()
+	}
+}
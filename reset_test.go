@@ -0,0 +1,41 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLexerReset(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	inputs := []string{"ab\nc1\nde\n", "xyz\n", ""}
+	for _, sync := range []bool{false, true} {
+		var l *Lexer
+		var err error
+		if sync {
+			l, err = NewLexerSync("TestLexerReset", strings.NewReader("unread\n"), rec)
+		} else {
+			l, err = NewLexer("TestLexerReset", strings.NewReader("unread\n"), rec)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.NextItem()
+		for _, input := range inputs {
+			expect := lexAll(t, "TestLexerReset", input, rec)
+			l.Reset("TestLexerReset", strings.NewReader(input))
+			var items []Item
+			for item := range l.Items() {
+				items = append(items, item)
+			}
+			if summarize(items) != summarize(expect) {
+				t.Errorf("sync %v, %q: expected %s, got %s", sync, input, summarize(expect), summarize(items))
+			}
+		}
+	}
+}
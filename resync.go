@@ -0,0 +1,64 @@
+package lexrec
+
+// Signature returns the first n bindings of rec, for use as a
+// record-start signature by Resync.  If n exceeds the number of
+// bindings, all of them are returned.
+func (rec Record) Signature(n int) []Binding {
+	if n > len(rec.States) {
+		n = len(rec.States)
+	}
+	return rec.States[:n]
+}
+
+// try runs the StateFns of sig against the input at the current
+// position, reporting whether all of them succeeded.  No items or
+// errors are emitted and the Lexer is left where it started.
+func (l *Lexer) try(sig []Binding) bool {
+	pos, start, rpos, width, eof := l.pos, l.start, l.rpos, l.width, l.eof
+	l.trial++
+	ok := true
+	for _, b := range sig {
+		if !b.StateFn(l, b.ItemType, false) {
+			ok = false
+			break
+		}
+	}
+	l.trial--
+	l.pos, l.start, l.rpos, l.width, l.eof = pos, start, rpos, width, eof
+	return ok
+}
+
+// Sync skips forward to the next plausible start of a record: a
+// position immediately following one of the runes in terminators at
+// which every StateFn of sig succeeds.  If terminators is empty the
+// positions following the Record's Terminator are candidates, or, if
+// it has none, every position is.  At least one rune is always
+// skipped, so that Sync makes progress when called at the start of a
+// corrupted record.  Sync returns false if the end of the input was
+// reached without finding a match.
+func (l *Lexer) Sync(terminators string, sig []Binding) bool {
+	for {
+		if !l.boundary(terminators) {
+			l.Skip()
+			return false
+		}
+		l.Skip()
+		if l.Peek() == EOF {
+			return false
+		}
+		if l.try(sig) {
+			return true
+		}
+	}
+}
+
+// Resync returns an ErrorFn that recovers from a malformed record by
+// calling Sync, skipping forward to the next position following one of
+// the runes in terminators at which sig matches, e.g.:
+//
+//	rec.ErrorFn = lexrec.Resync("\n", rec.Signature(3))
+func Resync(terminators string, sig []Binding) ErrorFn {
+	return func(l *Lexer) {
+		l.Sync(terminators, sig)
+	}
+}
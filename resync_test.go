@@ -0,0 +1,34 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestResync(t *testing.T) {
+	rec := Record{
+		Buflen: 1,
+		States: []Binding{
+			{ItemA, Accept("[", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("]", true), false},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	rec.ErrorFn = Resync("\n", rec.Signature(2))
+
+	// the second line is corrupted by an embedded newline, and the
+	// line fragment "x]" that follows does not begin with a
+	// signature, so it is skipped as well.
+	items := lexAll(t, "TestResync", "[1]\n[2\nx]\n[3]\n", rec)
+	expect := []ItemType{ItemB, ItemEOR, ItemB, ItemError, ItemB, ItemEOR, ItemEOF}
+	if len(items) != len(expect) {
+		t.Fatalf("expected %d items, got %v", len(expect), items)
+	}
+	for i := range expect {
+		if items[i].Type != expect[i] {
+			t.Errorf("item %d: expected type %d, got %q", i, expect[i], items[i])
+		}
+	}
+	if items[4].Value != "3" {
+		t.Errorf("expected to resume at record \"3\", got %q", items[4])
+	}
+}
@@ -0,0 +1,96 @@
+package lexrec
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// state runs the StateFn of b, the i'th Binding of the Record,
+// recovering using the Record's ErrorFn if it fails.  If the Record
+// salvages truncated records, errors emitted by a StateFn that fails
+// at the end of the input are discarded and the record is ended by an
+// ItemTruncated instead.
+func (l *Lexer) state(i int, b Binding) bool {
+	if !l.rec.Salvage {
+		if l.call(i, b) {
+			return true
+		}
+		l.recover()
+		return false
+	}
+
+	l.hold = true
+	success := l.call(i, b)
+	l.hold = false
+	held := l.held
+	l.held = l.held[:0]
+	if success {
+		for _, item := range held {
+			l.send(item)
+		}
+		return true
+	}
+	if l.Peek() == EOF {
+		l.truncate()
+		return false
+	}
+	for _, item := range held {
+		l.send(item)
+	}
+	l.recover()
+	return false
+}
+
+// truncate consumes the remainder of the input and emits it as an
+// ItemTruncated, marking the end of a record cut short by the end of
+// the input.
+func (l *Lexer) truncate() {
+	for l.Next() != EOF {
+	}
+	l.emit(Item{Type: ItemTruncated, Pos: l.tokenPos(), Value: string(l.buf[l.start:l.pos])})
+	l.Skip()
+}
+
+// recover runs the Record's ErrorFn after a StateFn has failed,
+// unless the Record has a Torn signature and the record is found to
+// have been torn by another.  A cooldown scan follows if the Record
+// has a Cooldown and enough consecutive records have now failed.  If
+// the Record has a Quarantine writer, the bytes of the record up to
+// the point at which lexing resumes are written to it.  If the Record
+// reports recoveries, an ItemRecovered follows, giving the position
+// at which lexing resumes and the number of bytes of the record, from
+// its start, that were lost.
+func (l *Lexer) recover() {
+	if l.rec.Torn != nil && l.tear() {
+		return
+	}
+	l.rec.ErrorFn(l)
+	l.fails++
+	if c := l.rec.Cooldown; c != nil && c.After > 0 && l.fails >= c.After {
+		l.cooldown(c)
+		l.fails = 0
+	}
+	pos := l.tokenPos()
+	if l.rec.Quarantine != nil {
+		l.quarantine(pos)
+	}
+	if l.rec.Recovered {
+		l.send(Item{Type: ItemRecovered, Pos: pos, Value: strconv.FormatInt(pos-l.recPos, 10)})
+	}
+}
+
+// quarantine writes the bytes of the current record, from its start
+// up to pos, to the Record's Quarantine writer.  A failed write is
+// reported as an ItemError.
+func (l *Lexer) quarantine(pos int64) {
+	from := l.pos - int(l.rpos-l.recPos)
+	to := from + int(pos-l.recPos)
+	if from < 0 || to <= from {
+		return
+	}
+	if _, err := l.rec.Quarantine.Write(l.buf[from:to]); err != nil {
+		state := l.errorState()
+		state.Cause = err
+		l.send(Item{Type: ItemError, Pos: l.recPos, Value: fmt.Sprintf("%s: quarantine: %v", l.name, err), Err: state})
+	}
+}
@@ -0,0 +1,90 @@
+package lexrec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSalvage(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Salvage: true,
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Quote, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+
+	tests := []struct {
+		input  string
+		expect []Item
+	}{
+		{"ab \"x\"\ncd ", []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemB, Pos: 3, Value: `"x"`}, {Type: ItemEOR, Pos: 7},
+			{Type: ItemA, Pos: 7, Value: "cd"}, {Type: ItemTruncated, Pos: 10}, {Type: ItemEOF, Pos: 10}}},
+		{"ab \"x", []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemTruncated, Pos: 3, Value: `"x`}, {Type: ItemEOF, Pos: 5}}},
+	}
+	for _, test := range tests {
+		items := lexAll(t, "TestSalvage", test.input, rec)
+		if len(items) != len(test.expect) {
+			t.Errorf("%q: expected %v, got %v", test.input, test.expect, items)
+			continue
+		}
+		for i := range items {
+			if !sameItem(items[i], test.expect[i]) {
+				t.Errorf("%q: expected %v, got %v", test.input, test.expect[i], items[i])
+			}
+		}
+	}
+}
+
+func TestRecovered(t *testing.T) {
+	rec := Record{
+		Buflen:    16,
+		ErrorFn:   SkipPast("\n"),
+		Recovered: true,
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestRecovered", "ab\nc1d\ne\n", rec)
+	var got []Item
+	for _, item := range items {
+		if item.Type == ItemRecovered {
+			got = append(got, item)
+		}
+	}
+	expect := []Item{{Type: ItemRecovered, Pos: 7, Value: "4"}}
+	if len(got) != len(expect) || !sameItem(got[0], expect[0]) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	var quarantine bytes.Buffer
+	rec := Record{
+		Buflen:     4,
+		ErrorFn:    SkipPast("\n"),
+		Quarantine: &quarantine,
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestQuarantine", "ab\nc1d\ne\nfg2h\ni\n", rec)
+	var got []string
+	for _, item := range items {
+		if item.Type == ItemA {
+			got = append(got, item.Value)
+		}
+	}
+	if strings.Join(got, ",") != "ab,c,e,fg,i" {
+		t.Errorf("expected items ab,c,e,fg,i, got %v", got)
+	}
+	if quarantine.String() != "c1d\nfg2h\n" {
+		t.Errorf("expected quarantined %q, got %q", "c1d\nfg2h\n", quarantine.String())
+	}
+}
@@ -0,0 +1,93 @@
+package lexrec
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SanitizeAction is what a Sanitizer does with the NUL (U+0000) and
+// replacement (U+FFFD) runes, and with bytes that are not valid UTF-8,
+// in the values of emitted items.
+type SanitizeAction int
+
+const (
+	SanitizeStrip   SanitizeAction = iota // remove the offending runes
+	SanitizeReplace                       // replace each offending rune with the Sanitizer's Replacement
+	SanitizeReject                        // emit an error in place of the item, failing the StateFn
+)
+
+// Sanitizer enforces a policy for NUL and replacement runes, which
+// many databases reject, in the values of the items emitted by the
+// StateFns it is applied to with Sanitize.  The counts are updated by
+// the Lexer's goroutine, and are only safe to read once ItemEOF has
+// been received.
+type Sanitizer struct {
+	Action      SanitizeAction // what to do with offending runes
+	Replacement string         // replacement for offending runes, for SanitizeReplace
+	Stripped    int64          // offending runes removed
+	Replaced    int64          // offending runes replaced
+	Rejected    int64          // items rejected
+}
+
+// Sanitize returns a StateFn that runs fn, applying s to the value of
+// each item it emits before any Mask or encryption.
+func Sanitize(fn StateFn, s *Sanitizer) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		prev := l.sanitizer
+		l.sanitizer, l.rejected = s, false
+		success := fn(l, t, emit)
+		l.sanitizer = prev
+		return success && !l.rejected
+	}
+}
+
+// offending reports whether r, decoded from a value, is subject to a
+// Sanitizer.  Invalid bytes decode as utf8.RuneError.
+func offending(r rune) bool {
+	return r == 0 || r == utf8.RuneError
+}
+
+// apply returns value with s applied to it, or false if it is
+// rejected.
+func (s *Sanitizer) apply(value string) (string, bool) {
+	if utf8.ValidString(value) && strings.IndexFunc(value, offending) < 0 {
+		return value, true
+	}
+	if s.Action == SanitizeReject {
+		s.Rejected++
+		return value, false
+	}
+	var sb strings.Builder
+	for _, r := range value {
+		if !offending(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		if s.Action == SanitizeReplace {
+			sb.WriteString(s.Replacement)
+			s.Replaced++
+		} else {
+			s.Stripped++
+		}
+	}
+	return sb.String(), true
+}
+
+// sanitize applies the Lexer's Sanitizer to item, emitting an error in
+// its place if it is rejected.
+func (l *Lexer) sanitize(item Item) (Item, bool) {
+	value, ok := l.sanitizer.apply(item.Value)
+	if !ok {
+		l.rejected = true
+		msg := fmt.Sprintf("%s: value contains NUL or invalid UTF-8: %q", l.name, item.Value)
+		state := l.errorState()
+		state.Cause = &SyntaxError{Pos: item.Pos, Msg: msg}
+		l.send(Item{Type: ItemError, Pos: item.Pos, Value: msg, Err: state})
+		return item, false
+	}
+	if value != item.Value {
+		item.Value, item.Bytes = value, nil
+	}
+	return item, true
+}
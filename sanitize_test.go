@@ -0,0 +1,43 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		s      *Sanitizer
+		expect []Item
+		counts [3]int64
+	}{
+		{&Sanitizer{Action: SanitizeStrip}, []Item{
+			{Type: ItemA, Pos: 0, Value: "abc"}, {Type: ItemEOR, Pos: 9},
+			{Type: ItemA, Pos: 9, Value: "ok"}, {Type: ItemEOR, Pos: 12}, {Type: ItemEOF, Pos: 12}},
+			[3]int64{3, 0, 0}},
+		{&Sanitizer{Action: SanitizeReplace, Replacement: "?"}, []Item{
+			{Type: ItemA, Pos: 0, Value: "a?b?c?"}, {Type: ItemEOR, Pos: 9},
+			{Type: ItemA, Pos: 9, Value: "ok"}, {Type: ItemEOR, Pos: 12}, {Type: ItemEOF, Pos: 12}},
+			[3]int64{0, 3, 0}},
+		{&Sanitizer{Action: SanitizeReject}, []Item{
+			{Type: ItemError, Pos: 0},
+			{Type: ItemA, Pos: 9, Value: "ok"}, {Type: ItemEOR, Pos: 12}, {Type: ItemEOF, Pos: 12}},
+			[3]int64{0, 0, 1}},
+	}
+	for _, test := range tests {
+		rec := Record{
+			Buflen:  16,
+			ErrorFn: SkipPast("\n"),
+			States: []Binding{
+				{ItemA, Sanitize(ExceptRun("\n", true), test.s), true},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		items := lexAll(t, "TestSanitize", "a\x00b\xffc�\nok\n", rec)
+		if summarize(items) != summarize(test.expect) {
+			t.Errorf("%d: expected %s, got %s", test.s.Action, summarize(test.expect), summarize(items))
+		}
+		counts := [3]int64{test.s.Stripped, test.s.Replaced, test.s.Rejected}
+		if counts != test.counts {
+			t.Errorf("%d: expected counts %v, got %v", test.s.Action, test.counts, counts)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package lexrec
+
+// Scratch is temporary storage owned by a single Lexer, for StateFns
+// that need working buffers, e.g., to decode escapes or normalize
+// numbers, without allocating on every call and without sharing state
+// between Lexers.  A Lexer's StateFns run in a single goroutine, so
+// the Scratch needs no locking, but its contents may be overwritten by
+// any StateFn: use it only within a single call.
+type Scratch struct {
+	Bytes  []byte                      // reusable byte buffer
+	Runes  []rune                      // reusable rune buffer
+	values map[interface{}]interface{} // per-Lexer values keyed by their owner
+}
+
+// Scratch returns the Lexer's Scratch, allocating it on first use.
+func (l *Lexer) Scratch() *Scratch {
+	if l.scratch == nil {
+		l.scratch = &Scratch{}
+	}
+	return l.scratch
+}
+
+// Reset truncates the Bytes and Runes buffers, retaining their
+// capacity.
+func (s *Scratch) Reset() {
+	s.Bytes = s.Bytes[:0]
+	s.Runes = s.Runes[:0]
+}
+
+// Value returns the value stored under key, calling init to create it
+// if it is not present.  This gives a StateFn per-Lexer state that
+// persists between calls; key should be a value private to the
+// StateFn, e.g., a pointer to a package level variable, so that it
+// does not collide with the keys of other StateFns.
+func (s *Scratch) Value(key interface{}, init func() interface{}) interface{} {
+	v, ok := s.values[key]
+	if !ok {
+		if s.values == nil {
+			s.values = make(map[interface{}]interface{})
+		}
+		v = init()
+		s.values[key] = v
+	}
+	return v
+}
@@ -0,0 +1,49 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScratch(t *testing.T) {
+	type counter struct{ n int }
+	var key int
+	// upper emits the current token upper cased, counting its calls
+	// in per-Lexer state.
+	upper := func(l *Lexer, t ItemType, emit bool) bool {
+		if !l.AcceptRun("abc") {
+			return false
+		}
+		s := l.Scratch()
+		s.Reset()
+		for _, b := range l.Bytes() {
+			s.Bytes = append(s.Bytes, b-'a'+'A')
+		}
+		s.Value(&key, func() interface{} { return &counter{} }).(*counter).n++
+		l.EmitValue(t, string(s.Bytes))
+		return true
+	}
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, upper, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexer("TestScratch", strings.NewReader("ab\nc\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for item := l.NextItem(); item.Type != ItemEOF; item = l.NextItem() {
+		if item.Type == ItemA {
+			values = append(values, item.Value)
+		}
+	}
+	if len(values) != 2 || values[0] != "AB" || values[1] != "C" {
+		t.Errorf("expected [AB C], got %v", values)
+	}
+	if n := l.Scratch().Value(&key, nil).(*counter).n; n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+}
@@ -0,0 +1,109 @@
+package lexrec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TermByte returns a Terminator matching the single byte b, which
+// need not be valid UTF-8 text, e.g., TermByte(0) for the output of
+// find -print0.
+func TermByte(b byte) Terminator {
+	return TermString(string([]byte{b}))
+}
+
+// nextByte consumes the next byte of the input, whether or not it
+// begins a valid UTF-8 encoding, returning EOF at the end of the
+// input.  Backup may be used to step back over it.
+func (l *Lexer) nextByte() int {
+	if l.Next() == EOF {
+		return EOF
+	}
+	if l.width > 1 {
+		l.pos -= l.width - 1
+		l.rpos -= int64(l.width - 1)
+		l.width = 1
+	}
+	return int(l.buf[l.pos-1])
+}
+
+// AcceptBytes consumes a run of bytes from the valid set, returning
+// true on success.  Unlike AcceptRun, the set is matched byte by
+// byte, and so may hold sentinel bytes that are not valid UTF-8.
+func (l *Lexer) AcceptBytes(valid string) bool {
+	for {
+		b := l.nextByte()
+		if b == EOF {
+			break
+		}
+		if strings.IndexByte(valid, byte(b)) < 0 {
+			l.Backup()
+			break
+		}
+	}
+	return l.pos > l.start
+}
+
+// ExceptBytes consumes a run of bytes that are not in the invalid
+// set, returning true on success.  Unlike ExceptRun, the set is
+// matched byte by byte, and so may hold sentinel bytes that are not
+// valid UTF-8.
+func (l *Lexer) ExceptBytes(invalid string) bool {
+	for {
+		b := l.nextByte()
+		if b == EOF {
+			break
+		}
+		if strings.IndexByte(invalid, byte(b)) >= 0 {
+			l.Backup()
+			break
+		}
+	}
+	return l.pos > l.start
+}
+
+// ExceptBytes returns a StateFn that consumes a run of bytes that are
+// not in the invalid set, e.g., a NUL-terminated field.  If needed is
+// true and if no bytes are consumed, an error is emitted.
+func ExceptBytes(invalid string, needed bool) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		if l.ExceptBytes(invalid) {
+			if emit {
+				l.Emit(t)
+			} else {
+				l.Skip()
+			}
+			return true
+		}
+		if needed {
+			l.Errorf("expected a byte outside the set %q, got %s", invalid, l.peekByte())
+		}
+		return false
+	}
+}
+
+// SkipPastBytes returns an ErrorFn that consumes a sequence of bytes
+// that are not in the set s, and one or more instances of the bytes in
+// the set s.  It is the byte-oriented form of SkipPast, for records
+// ended by sentinel bytes.
+func SkipPastBytes(s string) ErrorFn {
+	return func(l *Lexer) {
+		if l.ExceptBytes(s) {
+			l.Skip()
+		}
+		if l.AcceptBytes(s) {
+			l.Skip()
+		}
+	}
+}
+
+// peekByte returns a description of the next byte of the input for use
+// in error messages.
+func (l *Lexer) peekByte() string {
+	b := l.nextByte()
+	if b == EOF {
+		return "EOF"
+	}
+	l.Backup()
+	return fmt.Sprintf("\\x%02x", b)
+}
@@ -0,0 +1,51 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestSentinel(t *testing.T) {
+	tests := []struct {
+		rec    Record
+		input  string
+		expect []Item
+	}{
+		{Record{
+			Buflen:     16,
+			ErrorFn:    SkipRecord,
+			Terminator: TermByte(0),
+			States: []Binding{
+				{ItemA, ExceptBytes("\x00", true), true}},
+		}, "a b\x00c\x00", []Item{
+			{Type: ItemA, Pos: 0, Value: "a b"}, {Type: ItemEOR, Pos: 4},
+			{Type: ItemA, Pos: 4, Value: "c"}, {Type: ItemEOR, Pos: 6},
+			{Type: ItemEOF, Pos: 6}}},
+		{Record{
+			Buflen:     16,
+			ErrorFn:    SkipRecord,
+			Terminator: TermByte(0xff),
+			States: []Binding{
+				{ItemA, ExceptBytes("\xff", true), true}},
+		}, "x\xffyé\xff", []Item{
+			{Type: ItemA, Pos: 0, Value: "x"}, {Type: ItemEOR, Pos: 2},
+			{Type: ItemA, Pos: 2, Value: "yé"}, {Type: ItemEOR, Pos: 6},
+			{Type: ItemEOF, Pos: 6}}},
+		{Record{
+			Buflen:     16,
+			ErrorFn:    SkipPastBytes("\xff"),
+			Terminator: TermByte(0xff),
+			States: []Binding{
+				{ItemA, Letters, true}},
+		}, "ab\xff1\xff\xffcd\xff", []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 3},
+			{Type: ItemError, Pos: 3},
+			{Type: ItemA, Pos: 6, Value: "cd"}, {Type: ItemEOR, Pos: 9},
+			{Type: ItemEOF, Pos: 9}}},
+	}
+	for _, test := range tests {
+		items := lexAll(t, "TestSentinel", test.input, test.rec)
+		if summarize(items) != summarize(test.expect) {
+			t.Errorf("%q: expected %s, got %s", test.input, summarize(test.expect), summarize(items))
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package lexrec
+
+// Sequence returns a StateFn that applies each of fns in turn, and
+// emits the combined span as a single item, so that a compound field,
+// e.g., a bracketed timestamp, can be defined once and bound like any
+// other:
+//
+//	var timestamp = lexrec.Sequence(
+//		lexrec.Accept("[", true), lexrec.Digits, lexrec.Accept(":", true),
+//		lexrec.Digits, lexrec.Accept("]", true))
+//
+// If any of fns fails, its error is emitted, and the Lexer is rewound
+// to where the Sequence began.
+func Sequence(fns ...StateFn) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		pos, start, rpos, width, eof := l.pos, l.start, l.rpos, l.width, l.eof
+		failed := -1
+		l.trial++
+		for i, fn := range fns {
+			if !fn(l, t, false) {
+				failed = i
+				break
+			}
+		}
+		l.trial--
+		if failed >= 0 {
+			// replay the steps that succeeded, so that the one that
+			// failed reports its error where it occurred.
+			l.pos, l.start, l.rpos, l.width, l.eof = pos, start, rpos, width, eof
+			l.trial++
+			for _, fn := range fns[:failed] {
+				fn(l, t, false)
+			}
+			l.trial--
+			l.report(fns[failed], t)
+			l.pos, l.start, l.rpos, l.width, l.eof = pos, start, rpos, width, eof
+			return false
+		}
+		l.start = start
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
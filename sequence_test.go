@@ -0,0 +1,36 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestSequence(t *testing.T) {
+	timestamp := Sequence(
+		Accept("[", true), Digits, Accept(":", true),
+		Digits, Accept("]", true))
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, timestamp, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	items := lexAll(t, "TestSequence", "[12:30] ab\n[12-30] cd\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "[12:30]"}, {Type: ItemB, Pos: 8, Value: "ab"}, {Type: ItemEOR, Pos: 11},
+		{Type: ItemError, Pos: 14},
+		{Type: ItemEOF, Pos: 22}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range items {
+		if items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+	if items[3].Value != `expected character from the set ":", got '-'` {
+		t.Errorf("expected the error of the failing step, got %q", items[3].Value)
+	}
+}
@@ -0,0 +1,177 @@
+package lexrec
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// Sketch summarizes a stream of values in bounded memory.
+type Sketch interface {
+	Add(value string) // add a value to the summary; a value that is kept must be copied
+}
+
+// SketchMap attaches Sketches to item types.  Set a Record's Sketches
+// to have the value of each emitted item added to the Sketches of its
+// type, e.g.:
+//
+//	paths, hosts := lexrec.NewTopK(10), lexrec.NewHyperLogLog(14)
+//	rec.Sketches = lexrec.SketchMap{ItemRequestPath: {paths}, ItemRemoteHost: {hosts}}
+//
+// The Sketches are updated by the Lexer's goroutine, and are only safe
+// to read once ItemEOF has been received.
+type SketchMap map[ItemType][]Sketch
+
+// sketch adds the value of item to the Record's Sketches of its type.
+func (l *Lexer) sketch(item Item) {
+	for _, s := range l.rec.Sketches[item.Type] {
+		s.Add(item.Value)
+	}
+}
+
+// ValueCount is a value and the number of times it was seen.
+type ValueCount struct {
+	Value string
+	Count int64
+}
+
+// TopK estimates the most frequent values of a stream using a
+// count-min sketch, tracking the k values with the highest estimated
+// counts.  Estimated counts are never less than the true counts.
+type TopK struct {
+	k      int
+	seeds  []maphash.Seed
+	counts [][]int64
+	top    map[string]*int64
+}
+
+// NewTopK returns a TopK tracking the k most frequent values, using a
+// count-min sketch of 4 rows of 2048 counters.
+func NewTopK(k int) *TopK {
+	t := &TopK{k: k, top: make(map[string]*int64, k+1)}
+	for i := 0; i < 4; i++ {
+		t.seeds = append(t.seeds, maphash.MakeSeed())
+		t.counts = append(t.counts, make([]int64, 2048))
+	}
+	return t
+}
+
+// Add counts an occurrence of value.
+func (t *TopK) Add(value string) {
+	est := int64(math.MaxInt64)
+	for i, seed := range t.seeds {
+		row := t.counts[i]
+		j := maphash.String(seed, value) % uint64(len(row))
+		row[j]++
+		if row[j] < est {
+			est = row[j]
+		}
+	}
+	// values may be backed by an Arena or the read buffer, so a
+	// value is copied when it is added to the top k.
+	if n, ok := t.top[value]; ok {
+		*n = est
+		return
+	}
+	if len(t.top) < t.k {
+		t.top[strings.Clone(value)] = &est
+		return
+	}
+	min, minValue := est, ""
+	for v, n := range t.top {
+		if *n < min {
+			min, minValue = *n, v
+		}
+	}
+	if min < est {
+		delete(t.top, minValue)
+		t.top[strings.Clone(value)] = &est
+	}
+}
+
+// Count returns the estimated number of occurrences of value.
+func (t *TopK) Count(value string) int64 {
+	est := int64(math.MaxInt64)
+	for i, seed := range t.seeds {
+		row := t.counts[i]
+		if n := row[maphash.String(seed, value)%uint64(len(row))]; n < est {
+			est = n
+		}
+	}
+	return est
+}
+
+// Top returns up to k of the most frequent values seen, with their
+// estimated counts, most frequent first.
+func (t *TopK) Top() []ValueCount {
+	top := make([]ValueCount, 0, len(t.top))
+	for v, n := range t.top {
+		top = append(top, ValueCount{v, *n})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Value < top[j].Value
+	})
+	return top
+}
+
+// HyperLogLog estimates the number of distinct values in a stream.
+type HyperLogLog struct {
+	p    uint8
+	seed maphash.Seed
+	regs []uint8
+}
+
+// NewHyperLogLog returns a HyperLogLog using 2^p registers, where p
+// is between 4 and 16.  The standard error of its estimates is about
+// 1.04/sqrt(2^p), e.g., 0.8% for p = 14.
+func NewHyperLogLog(p uint8) *HyperLogLog {
+	if p < 4 {
+		p = 4
+	} else if p > 16 {
+		p = 16
+	}
+	return &HyperLogLog{p: p, seed: maphash.MakeSeed(), regs: make([]uint8, 1<<p)}
+}
+
+// Add records an occurrence of value.
+func (h *HyperLogLog) Add(value string) {
+	x := maphash.String(h.seed, value)
+	i := x >> (64 - h.p)
+	rho := uint8(bits.LeadingZeros64(x<<h.p|1<<(h.p-1)) + 1)
+	if rho > h.regs[i] {
+		h.regs[i] = rho
+	}
+}
+
+// Count returns the estimated number of distinct values seen.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.regs))
+	var alpha float64
+	switch len(h.regs) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	sum, zeros := 0.0, 0
+	for _, r := range h.regs {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	est := alpha * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		est = m * math.Log(m/float64(zeros))
+	}
+	return uint64(est + 0.5)
+}
@@ -0,0 +1,43 @@
+package lexrec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSketches(t *testing.T) {
+	top, distinct := NewTopK(2), NewHyperLogLog(14)
+	rec := Record{
+		Buflen:   16,
+		ErrorFn:  SkipPast("\n"),
+		Sketches: SketchMap{ItemA: {top, distinct}},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	lexAll(t, "TestSketches", "a\nb\nc\nb\na\nb\nd\n", rec)
+	expect := []ValueCount{{"b", 3}, {"a", 2}}
+	if got := top.Top(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected top %v, got %v", expect, got)
+	}
+	if n := top.Count("c"); n != 1 {
+		t.Errorf("expected a count of 1 for c, got %d", n)
+	}
+	if n := distinct.Count(); n != 4 {
+		t.Errorf("expected 4 distinct values, got %d", n)
+	}
+}
+
+func TestHyperLogLog(t *testing.T) {
+	h := NewHyperLogLog(14)
+	for i := 0; i < 100000; i++ {
+		h.Add(fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+		h.Add(strings.Repeat("x", i%10))
+	}
+	n := h.Count()
+	if n < 97000 || n > 103000 {
+		t.Errorf("expected about 100010 distinct values, got %d", n)
+	}
+}
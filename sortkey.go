@@ -0,0 +1,83 @@
+package lexrec
+
+import (
+	"io"
+	"strings"
+)
+
+// SortKey holds the composite sort key and the byte span of a record.
+type SortKey struct {
+	Key  string // values of the key fields, in key order, joined by the separator
+	Span Span   // extent of the record in the input
+}
+
+// SortKeys lexes r using rec, calling fn with the SortKey of each
+// successfully lexed record, so that external tools can reorder a
+// large input by seeking to each Span without parsing every field
+// again.  The key is built from the values of the fields of type keys,
+// in the order given, joined by sep; only those fields are emitted,
+// all other bindings are consumed without allocating item values.
+// Records that fail to lex are skipped, but an error reading r ends
+// the input and is returned.  Once fn returns an error it is no longer
+// called, and SortKeys returns that error once the input has been
+// consumed.  The Record's own OnSpan, if any, is still called for each
+// record.
+func SortKeys(r io.Reader, rec Record, sep string, keys []ItemType, fn func(k SortKey) error) (err error) {
+	order := make(map[ItemType]int, len(keys))
+	for i, t := range keys {
+		order[t] = i
+	}
+	values := make([]string, len(keys))
+
+	states := make([]Binding, len(rec.States))
+	for i, b := range rec.States {
+		if _, ok := order[b.ItemType]; ok {
+			b.StateFn = captureKey(b.StateFn, values, order)
+			b.Emit = true
+		} else {
+			b.Emit = false
+		}
+		states[i] = b
+	}
+	rec.States = states
+	onSpan := rec.OnSpan
+	rec.OnSpan = func(s Span) {
+		if onSpan != nil {
+			onSpan(s)
+		}
+		if !s.Err && err == nil {
+			err = fn(SortKey{Key: strings.Join(values, sep), Span: s})
+		}
+		for i := range values {
+			values[i] = ""
+		}
+	}
+
+	l, lerr := NewLexer("SortKeys", r, rec)
+	if lerr != nil {
+		return lerr
+	}
+	for _, ok := l.nextItem(); ok; _, ok = l.nextItem() {
+	}
+	if rerr := l.Close(); err == nil {
+		err = rerr
+	}
+	return
+}
+
+// captureKey returns a StateFn that runs fn, storing the values it
+// emits into values at the position assigned to their ItemType by
+// order rather than sending them to the client.
+func captureKey(fn StateFn, values []string, order map[ItemType]int) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		prev := l.capture
+		l.capture = func(item Item) {
+			if i, ok := order[item.Type]; ok {
+				values[i] = item.Value
+			}
+		}
+		success := fn(l, t, emit)
+		l.capture = prev
+		return success
+	}
+}
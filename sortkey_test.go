@@ -0,0 +1,63 @@
+package lexrec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSortKeys(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	var keys []SortKey
+	err := SortKeys(strings.NewReader("ab 12\n! 1\nc 3\n"), rec, "|", []ItemType{ItemB, ItemA}, func(k SortKey) error {
+		keys = append(keys, k)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []SortKey{
+		{"12|ab", Span{1, 0, 6, false}},
+		{"3|c", Span{3, 10, 4, false}},
+	}
+	if len(keys) != len(expect) {
+		t.Fatalf("expected %d keys, got %v", len(expect), keys)
+	}
+	for i := range expect {
+		if keys[i] != expect[i] {
+			t.Errorf("expected %v, got %v", expect[i], keys[i])
+		}
+	}
+}
+
+func TestSortKeysReadError(t *testing.T) {
+	spans := 0
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		OnSpan:  func(s Span) { spans++ },
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	failure := errors.New("disk on fire")
+	var keys []SortKey
+	err := SortKeys(&errReader{"ab\ncd\n", failure}, rec, "|", []ItemType{ItemA}, func(k SortKey) error {
+		keys = append(keys, k)
+		return nil
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the read error, got %v", err)
+	}
+	if len(keys) != 2 || spans < 2 {
+		t.Errorf("expected 2 keys and the Record's OnSpan called for each, got %v and %d spans", keys, spans)
+	}
+}
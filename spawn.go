@@ -0,0 +1,24 @@
+//go:build !lexrec_sync && !wasm
+
+package lexrec
+
+// Synchronous reports whether the package was built without
+// goroutines, using the lexrec_sync build tag or for a wasm target, in
+// which case every Lexer runs in the caller's goroutine.
+const Synchronous = false
+
+// spawn starts the goroutine that lexes the input, sending each item
+// on the Lexer's channel.
+func (l *Lexer) spawn() {
+	l.items = make(chan Item)
+	go l.run()
+}
+
+// spawnRun is like spawn, but drives the Lexer using runFn.
+func (l *Lexer) spawnRun(runFn RunFn) {
+	l.items = make(chan Item)
+	go func(l *Lexer, runFn RunFn) {
+		defer close(l.items)
+		runFn(l)
+	}(l, runFn)
+}
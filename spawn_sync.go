@@ -0,0 +1,27 @@
+//go:build lexrec_sync || wasm
+
+package lexrec
+
+// Synchronous reports whether the package was built without
+// goroutines, using the lexrec_sync build tag or for a wasm target, in
+// which case every Lexer runs in the caller's goroutine.
+const Synchronous = true
+
+// spawn prepares the Lexer to run in the caller's goroutine, as one
+// returned by NewLexerSync does, lexing as much of the input as is
+// needed each time an item is read.  No goroutine or channel is
+// created.
+func (l *Lexer) spawn() {
+	l.out = func(item Item) {
+		l.queue = append(l.queue, item)
+	}
+}
+
+// spawnRun is like spawn, but since runFn cannot be suspended between
+// items it is run to completion before spawnRun returns, queueing
+// every item lexed from the input.
+func (l *Lexer) spawnRun(runFn RunFn) {
+	l.spawn()
+	runFn(l)
+	l.ended = true
+}
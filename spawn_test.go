@@ -0,0 +1,82 @@
+package lexrec
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSpawnMatchesSync(t *testing.T) {
+	input := "aaa\nbbb\nccc\n"
+	sync, err := NewLexerSync("TestSpawnMatchesSync", strings.NewReader(input), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []Item
+	for sync.Scan() {
+		want = append(want, sync.Item())
+	}
+
+	l, err := NewLexer("TestSpawnMatchesSync", strings.NewReader(input), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (l.items == nil) != Synchronous {
+		t.Errorf("expected a channel only when Synchronous is false, got %v", l.items)
+	}
+	var got []Item
+	for {
+		item := l.NextItem()
+		got = append(got, item)
+		if item.Type == ItemEOF {
+			break
+		}
+	}
+	if summarize(got) != summarize(want) {
+		t.Errorf("expected %s, got %s", summarize(want), summarize(got))
+	}
+}
+
+func TestSpawnRun(t *testing.T) {
+	runFn := func(l *Lexer) {
+		for l.AcceptRun("a") {
+			l.Emit(ItemA)
+			l.AcceptRun("\n")
+			l.Skip()
+		}
+		l.Emit(ItemEOF)
+	}
+	l, err := NewLexerRun("TestSpawnRun", strings.NewReader("aa\na\n"), aRecord, runFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []Item
+	for item := l.NextItem(); item.Type != ItemEOF; item = l.NextItem() {
+		got = append(got, item)
+	}
+	want := summarize([]Item{{Type: ItemA, Pos: 0, Value: "aa"}, {Type: ItemA, Pos: 3, Value: "a"}})
+	if summarize(got) != want {
+		t.Errorf("expected %s, got %s", want, summarize(got))
+	}
+}
+
+func TestSpawnCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l, err := NewLexerContext(ctx, "TestSpawnCanceled", strings.NewReader("a\nb\n"), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	for {
+		item := l.NextItem()
+		if item.Type == ItemEOF {
+			t.Fatalf("expected cancellation, got %v", item)
+		}
+		if item.Type == ItemError && item.Err.Cause == context.Canceled {
+			break
+		}
+	}
+	if !finished(l) {
+		t.Errorf("expected the lexer to have finished after cancellation")
+	}
+}
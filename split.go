@@ -0,0 +1,58 @@
+package lexrec
+
+import (
+	"io"
+	"strings"
+)
+
+// Splitter demultiplexes the records of a Lexer into separate
+// writers chosen by the value of a key field, e.g., one file per
+// virtual host or per status class.  Each record is re-serialized as
+// the values of its emitted items joined by Sep and terminated by a
+// newline.
+type Splitter struct {
+	Key   ItemType                             // item type of the field selecting the output
+	Group func(value string) string            // maps a key value to an output name; nil uses the value as is
+	Sep   string                               // separator written between item values
+	Open  func(name string) (io.Writer, error) // returns the writer for an output name, called once per name
+}
+
+// Split reads records from l until ItemEOF, writing each successfully
+// lexed record to the output selected by its Key field.  Records
+// without a Key field are written to the output named "".  Records
+// that fail to lex are skipped.  Split returns the number of records
+// written and the first error returned by Open or a writer.
+func (s *Splitter) Split(l *Lexer) (records int, err error) {
+	outputs := make(map[string]io.Writer)
+	values := []string{}
+	for {
+		items, failed, eof := readRecord(l)
+		if !failed && len(items) > 0 {
+			name := ""
+			values = values[:0]
+			for _, item := range items {
+				if item.Type == s.Key {
+					name = item.Value
+				}
+				values = append(values, item.Value)
+			}
+			if s.Group != nil {
+				name = s.Group(name)
+			}
+			w, ok := outputs[name]
+			if !ok {
+				if w, err = s.Open(name); err != nil {
+					return
+				}
+				outputs[name] = w
+			}
+			if _, err = io.WriteString(w, strings.Join(values, s.Sep)+"\n"); err != nil {
+				return
+			}
+			records++
+		}
+		if eof {
+			return
+		}
+	}
+}
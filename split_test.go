@@ -0,0 +1,48 @@
+package lexrec
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSplitter(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexer("TestSplitter", strings.NewReader("a 200\nb 404\n? 1\na 403\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := map[string]*bytes.Buffer{}
+	s := Splitter{
+		Key:   ItemB,
+		Group: func(value string) string { return value[:1] + "xx" },
+		Sep:   ",",
+		Open: func(name string) (io.Writer, error) {
+			outputs[name] = new(bytes.Buffer)
+			return outputs[name], nil
+		},
+	}
+	n, err := s.Split(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 records, got %d", n)
+	}
+	if got := outputs["2xx"].String(); got != "a,200\n" {
+		t.Errorf("expected 2xx output \"a,200\\n\", got %q", got)
+	}
+	if got := outputs["4xx"].String(); got != "b,404\na,403\n" {
+		t.Errorf("expected 4xx output \"b,404\\na,403\\n\", got %q", got)
+	}
+}
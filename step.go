@@ -0,0 +1,117 @@
+package lexrec
+
+import (
+	"fmt"
+	"io"
+)
+
+// Step describes the outcome of a single call to Stepper.Step.
+type Step struct {
+	State   int    // index of the Binding that ran, or -1 if the end of the input was reached
+	Text    string // text consumed by the StateFn
+	Success bool   // true if the StateFn succeeded
+	Skipped string // text consumed by the ErrorFn if the StateFn failed
+	Items   []Item // items emitted during the step
+}
+
+// Stepper runs a Record's StateFns one at a time, in the caller's
+// goroutine, for use when debugging a Record that mis-parses its
+// input.
+type Stepper struct {
+	l     *Lexer
+	state int  // index of the next Binding to run
+	done  bool // true once ItemEOF has been emitted
+}
+
+// NewStepper returns a Stepper for rec records from the UTF-8 reader
+// r.  The name is only used for debugging messages.
+func NewStepper(name string, r io.Reader, rec Record) (s *Stepper, err error) {
+	if len(rec.States) == 0 {
+		err = fmt.Errorf("rec.states must not be empty.")
+		return
+	}
+	if rec.Buflen < 1 {
+		err = fmt.Errorf("rec.Buflen must be > 0: %d", rec.Buflen)
+		return
+	}
+	if rec.ErrorFn == nil {
+		err = fmt.Errorf("rec.ErrorFn must not be nil")
+		return
+	}
+	if rec.ZeroCopy {
+		err = fmt.Errorf("rec.ZeroCopy requires NewLexerSync")
+		return
+	}
+	s = &Stepper{
+		l: &Lexer{
+			name: name,
+			r:    r,
+			rec:  rec,
+			next: make([]byte, rec.Buflen),
+		},
+	}
+	return
+}
+
+// Step runs the next StateFn of the Record, and if it fails, the
+// Record's ErrorFn, returning what was consumed and emitted.  The
+// ItemEOR ending a record is included in the Items of the step that
+// completes it.  Once the input is exhausted, Step returns a Step
+// with State -1 holding the ItemEOF, and ok is false on every call
+// after that.
+func (s *Stepper) Step() (step Step, ok bool) {
+	if s.done {
+		return Step{State: -1}, false
+	}
+	l := s.l
+	if l.pos == l.start && (s.state == 0 || l.rec.Quarantine == nil) {
+		// give Skip a chance to shift the buffer, which it
+		// won't do during the step, nor during a record that
+		// may have to be quarantined.
+		l.Skip()
+	}
+	if s.state == 0 {
+		l.recPos = l.tokenPos()
+	}
+	l.out = func(item Item) {
+		step.Items = append(step.Items, item)
+	}
+	l.keep = true
+	defer func() {
+		l.out = nil
+		l.keep = false
+	}()
+
+	if s.state == 0 && l.Peek() == EOF {
+		s.done = true
+		l.Emit(ItemEOF)
+		return Step{State: -1, Success: true, Items: step.Items}, true
+	}
+
+	b := l.rec.States[s.state]
+	step.State = s.state
+	from := l.pos
+	step.Success = l.call(s.state, b)
+	step.Text = string(l.buf[from:l.pos])
+	if !step.Success {
+		from = l.pos
+		l.recover()
+		step.Skipped = string(l.buf[from:l.pos])
+		s.state = 0
+		return step, true
+	}
+	s.state++
+	if s.state == len(l.rec.States) {
+		s.state = 0
+		from = l.pos
+		if !l.terminate() {
+			step.Success = false
+			step.Skipped = string(l.buf[from:l.pos])
+			return step, true
+		}
+		step.Text += string(l.buf[from:l.pos])
+		l.Emit(ItemEOR)
+		l.fails = 0
+	}
+	return step, true
+}
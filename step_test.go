@@ -0,0 +1,49 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStepper(t *testing.T) {
+	rec := Record{
+		Buflen:  1,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	s, err := NewStepper("TestStepper", strings.NewReader("ab\n1\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []Step{
+		{State: 0, Text: "ab", Success: true, Items: []Item{{Type: ItemA, Pos: 0, Value: "ab"}}},
+		{State: 1, Text: "\n", Success: true, Items: []Item{{Type: ItemEOR, Pos: 3}}},
+		{State: 0, Text: "", Success: false, Skipped: "1\n", Items: []Item{{Type: ItemError, Pos: 3, Value: `expected letter, got '1'`, Err: Error{State: 0, Binding: "lexrec.Letters"}}}},
+		{State: -1, Success: true, Items: []Item{{Type: ItemEOF, Pos: 5}}},
+	}
+	for i, e := range expect {
+		step, ok := s.Step()
+		if !ok {
+			t.Fatalf("step %d: unexpected end of steps", i)
+		}
+		if step.State != e.State || step.Text != e.Text || step.Success != e.Success || step.Skipped != e.Skipped {
+			t.Errorf("step %d: expected %+v, got %+v", i, e, step)
+		}
+		if len(step.Items) != len(e.Items) {
+			t.Errorf("step %d: expected items %v, got %v", i, e.Items, step.Items)
+			continue
+		}
+		for j := range e.Items {
+			step.Items[j].Err.Cause = nil
+			if !sameItem(step.Items[j], e.Items[j]) {
+				t.Errorf("step %d: expected item %v, got %v", i, e.Items[j], step.Items[j])
+			}
+		}
+	}
+	if _, ok := s.Step(); ok {
+		t.Errorf("expected no steps after ItemEOF")
+	}
+}
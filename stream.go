@@ -0,0 +1,145 @@
+package lexrec
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// decoder sets the fields of a struct from the items of a record.
+type decoder struct {
+	fields map[ItemType][]int // index of the struct field set by each item type
+}
+
+// newDecoder returns a decoder for the struct type t, matching each of
+// its exported fields to the item type named, in names, by the field's
+// lexrec tag or, if it has none, by the field's name, ignoring case.
+// Fields tagged "-", and fields with no matching item type, are left
+// unset.
+func newDecoder(t reflect.Type, names NameMap) (*decoder, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot decode records into %s: not a struct", t)
+	}
+	d := &decoder{fields: make(map[ItemType][]int)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("lexrec")
+		if tag == "-" {
+			continue
+		}
+		for it, name := range names {
+			if (tag != "" && name == tag) || (tag == "" && strings.EqualFold(name, f.Name)) {
+				d.fields[it] = f.Index
+			}
+		}
+	}
+	return d, nil
+}
+
+// decode sets the fields of v, a struct, from the values of items.
+func (d *decoder) decode(items []Item, v reflect.Value) error {
+	for _, item := range items {
+		index, ok := d.fields[item.Type]
+		if !ok {
+			continue
+		}
+		f := v.FieldByIndex(index)
+		if err := setField(f, item.Value); err != nil {
+			return fmt.Errorf("field %s at %d: %v", v.Type().FieldByIndex(index).Name, item.Pos, err)
+		}
+	}
+	return nil
+}
+
+// setField parses value into f according to its type.
+func setField(f reflect.Value, value string) error {
+	if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+	return nil
+}
+
+// Stream decodes each record lexed by l into a T, a struct type, and
+// sends it on the returned channel, which is closed once the input has
+// been consumed.  The fields of T are matched to the names of the
+// Record's item types, as given by its Names, using each field's
+// lexrec tag, e.g., `lexrec:"status"`, or, if it has none, the field's
+// name, ignoring case.  Fields of string, integer, floating point and
+// boolean types are supported, as are fields whose pointer implements
+// encoding.TextUnmarshaler.  If onError is not nil it is called with
+// the items of each record that fails to lex or to decode, which is
+// then skipped, and with a nil slice if T cannot be decoded at all.
+// The channel must be read until it is closed.
+func Stream[T any](l *Lexer, onError func(items []Item, err error)) <-chan T {
+	ch := make(chan T)
+	// unlike reflect.TypeOf(zero), this is not nil for an
+	// interface type such as any.
+	d, err := newDecoder(reflect.TypeOf((*T)(nil)).Elem(), l.rec.Names)
+	go func() {
+		defer close(ch)
+		if err != nil {
+			l.Close()
+			if onError != nil {
+				onError(nil, err)
+			}
+			return
+		}
+		for {
+			items, failed, eof := readRecord(l)
+			switch {
+			case failed:
+				if onError != nil {
+					onError(items, items[len(items)-1].cause())
+				}
+			case len(items) > 0:
+				var v T
+				if err := d.decode(items, reflect.ValueOf(&v).Elem()); err != nil {
+					if onError != nil {
+						onError(items, err)
+					}
+				} else {
+					ch <- v
+				}
+			}
+			if eof {
+				return
+			}
+		}
+	}()
+	return ch
+}
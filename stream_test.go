@@ -0,0 +1,70 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+type streamRecord struct {
+	Name   string
+	Status int `lexrec:"status"`
+	Other  bool
+}
+
+func TestStream(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Names:   NameMap{ItemA: "name", ItemB: "status"},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, ExceptRun("\n", true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	l, err := NewLexer("TestStream", strings.NewReader("ab 200\n1 2\ncd x\nef 404\n"), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failures []string
+	onError := func(items []Item, err error) {
+		failures = append(failures, summarize(items[:1]))
+	}
+	var got []streamRecord
+	for v := range Stream[streamRecord](l, onError) {
+		got = append(got, v)
+	}
+	expect := []streamRecord{{"ab", 200, false}, {"ef", 404, false}}
+	if len(got) != len(expect) || got[0] != expect[0] || got[1] != expect[1] {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+	if len(failures) != 2 {
+		t.Errorf("expected 2 failed records, got %v", failures)
+	}
+}
+
+func TestStreamNotStruct(t *testing.T) {
+	l, err := NewLexer("TestStreamNotStruct", strings.NewReader("a\n"), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failure error
+	for range Stream[int](l, func(items []Item, err error) { failure = err }) {
+	}
+	if failure == nil {
+		t.Errorf("expected an error decoding into an int")
+	}
+}
+
+func TestStreamInterface(t *testing.T) {
+	l, err := NewLexer("TestStreamInterface", strings.NewReader("a\n"), aRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failure error
+	for range Stream[any](l, func(items []Item, err error) { failure = err }) {
+	}
+	if failure == nil {
+		t.Errorf("expected an error decoding into an interface")
+	}
+}
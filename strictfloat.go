@@ -0,0 +1,56 @@
+package lexrec
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// StrictFloat consumes a floating point number whose syntax is exactly
+// that accepted by strconv.ParseFloat, so that every token it emits
+// converts without error: decimal and hexadecimal mantissas and
+// exponents, underscores between digits, and "Inf", "Infinity" and
+// "NaN" in any case.  Unlike Number, it accepts nothing that
+// ParseFloat rejects, and unlike Float, it accepts everything
+// ParseFloat does.  A number too large for a float64 is an error.  The
+// item is emitted as it appeared in the input.
+func StrictFloat(l *Lexer, t ItemType, emit bool) (success bool) {
+	prev := rune(EOF)
+	for {
+		r := l.Next()
+		if r == '+' || r == '-' {
+			if l.Size() > 1 && !strings.ContainsRune("eEpP", prev) {
+				l.Backup()
+				break
+			}
+		} else if !isFloatRune(r) {
+			l.Backup()
+			break
+		}
+		prev = r
+	}
+	if l.Size() == 0 {
+		l.unexpected("number")
+		return false
+	}
+	if _, err := strconv.ParseFloat(string(l.Bytes()), 64); err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			l.Errorf("number %q out of range", l.Bytes())
+		} else {
+			l.Errorf("bad number syntax: %q", l.Bytes())
+		}
+		return false
+	}
+	if emit {
+		l.Emit(t)
+	} else {
+		l.Skip()
+	}
+	return true
+}
+
+// isFloatRune reports whether r may appear in a number accepted by
+// strconv.ParseFloat, other than as a sign.
+func isFloatRune(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '.' || r == '_'
+}
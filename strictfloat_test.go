@@ -0,0 +1,39 @@
+package lexrec
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestStrictFloat(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemEmit, StrictFloat, true},
+			{ItemIgnore, Accept(" \n", true), false}},
+	}
+	valid := []string{"1", "-1.5", "+.5", "5.", "1e10", "1E-5", "1_000.5", "0x1p-2",
+		"0X1.8P+3", "0x.8p1", "inf", "-Infinity", "NaN", "00.5"}
+	invalid := []string{"0x1e", "-nan", "1.5e", "1__0", "1e+_5", "0b101", "1e400", "abc", "-", "."}
+	for _, s := range valid {
+		items := lexAll(t, "TestStrictFloat", s+"\n", rec)
+		if items[0].Type != ItemEmit || items[0].Value != s {
+			t.Errorf("%q: expected it to be accepted, got %v", s, items[0])
+			continue
+		}
+		if _, err := strconv.ParseFloat(items[0].Value, 64); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	for _, s := range invalid {
+		items := lexAll(t, "TestStrictFloat", s+"\n", rec)
+		if items[0].Type != ItemError {
+			t.Errorf("%q: expected an error, got %v", s, items[0])
+		}
+	}
+	items := lexAll(t, "TestStrictFloat", "1.5-2\n", rec)
+	if items[0].Value != "1.5" || items[1].Type != ItemError {
+		t.Errorf("expected 1.5 followed by an error, got %v", items)
+	}
+}
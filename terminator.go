@@ -0,0 +1,145 @@
+package lexrec
+
+import (
+	"strings"
+)
+
+// Terminator defines the end of a record.  It reports whether the
+// input at the current position of l begins with a record
+// terminator, and if so consumes it.  Otherwise it must leave l where
+// it was.
+type Terminator func(l *Lexer) bool
+
+// newline is the terminator assumed by SkipRecord for a Record that
+// does not define one.
+var newline = TermRune('\n')
+
+// TermRune returns a Terminator matching the single rune r.
+func TermRune(r rune) Terminator {
+	return TermString(string(r))
+}
+
+// TermString returns a Terminator matching the exact, non-empty,
+// sequence of bytes s, e.g., "\r\n".
+func TermString(s string) Terminator {
+	return func(l *Lexer) bool {
+		return l.acceptString(s)
+	}
+}
+
+// TermFunc returns a Terminator matching a run of one or more runes
+// for which fn returns true.
+func TermFunc(fn func(r rune) bool) Terminator {
+	return func(l *Lexer) bool {
+		n := 0
+		for {
+			r := l.Next()
+			if r == EOF || !fn(r) {
+				break
+			}
+			n++
+		}
+		l.Backup()
+		return n > 0
+	}
+}
+
+// acceptString consumes s if the input at the current position begins
+// with it, comparing bytes rather than runes.
+func (l *Lexer) acceptString(s string) bool {
+	pos, rpos, width, eof := l.pos, l.rpos, l.width, l.eof
+	for l.pos-pos < len(s) && l.Next() != EOF {
+	}
+	if l.pos-pos >= len(s) && string(l.buf[pos:pos+len(s)]) == s {
+		l.rpos -= int64(l.pos - (pos + len(s)))
+		l.pos = pos + len(s)
+		return true
+	}
+	l.pos, l.rpos, l.width, l.eof = pos, rpos, width, eof
+	return false
+}
+
+// terminate consumes the Record's Terminator, if it has one, after the
+// last Binding of a record has succeeded.  A missing terminator is
+// reported as an error and recovered from using the Record's ErrorFn,
+// unless the input has ended.
+func (l *Lexer) terminate() bool {
+	if l.rec.Terminator == nil {
+		return true
+	}
+	if l.rec.Terminator(l) {
+		l.Skip()
+		return true
+	}
+	if l.Peek() == EOF {
+		return true
+	}
+	l.unexpected("record terminator")
+	l.recover()
+	return false
+}
+
+// boundary advances past the next record boundary, which is one or
+// more of the runes in terminators or, if terminators is empty, the
+// Record's Terminator.  If neither is defined every position is a
+// boundary, and a single rune is consumed.  It returns false if the
+// end of the input was reached first.
+func (l *Lexer) boundary(terminators string) bool {
+	switch {
+	case terminators != "":
+		for {
+			r := l.Next()
+			if r == EOF {
+				return false
+			}
+			if strings.IndexRune(terminators, r) >= 0 {
+				l.AcceptRun(terminators)
+				return true
+			}
+		}
+	case l.rec.Terminator != nil:
+		for !l.rec.Terminator(l) {
+			if l.nextByte() == EOF {
+				return false
+			}
+		}
+		return true
+	default:
+		return l.Next() != EOF
+	}
+}
+
+// toTerminator consumes the input up to, but not including, the next
+// record terminator, as defined by the Record's Terminator or, if it
+// has none, a newline.
+func (l *Lexer) toTerminator() {
+	term := l.rec.Terminator
+	if term == nil {
+		term = newline
+	}
+	for {
+		pos, rpos, width := l.pos, l.rpos, l.width
+		if term(l) {
+			l.pos, l.rpos, l.width = pos, rpos, width
+			return
+		}
+		if l.nextByte() == EOF {
+			return
+		}
+	}
+}
+
+// SkipRecord is an ErrorFn that skips past the next record terminator,
+// as defined by the Record's Terminator or, if it has none, a newline.
+func SkipRecord(l *Lexer) {
+	term := l.rec.Terminator
+	if term == nil {
+		term = newline
+	}
+	for !term(l) {
+		if l.nextByte() == EOF {
+			break
+		}
+	}
+	l.Skip()
+}
@@ -0,0 +1,76 @@
+package lexrec
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// summarize returns the types and values of items, omitting error
+// messages.
+func summarize(items []Item) string {
+	s := []string{}
+	for _, item := range items {
+		if item.Type == ItemError {
+			s = append(s, "error")
+			continue
+		}
+		s = append(s, fmt.Sprintf("%d:%q", item.Type, item.Value))
+	}
+	return strings.Join(s, " ")
+}
+
+func TestTerminator(t *testing.T) {
+	tests := []struct {
+		term   Terminator
+		input  string
+		expect []Item
+	}{
+		{TermString("\r\n"), "ab\r\nc1\r\nef", []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 4},
+			{Type: ItemA, Pos: 4, Value: "c"}, {Type: ItemError, Pos: 6},
+			{Type: ItemA, Pos: 8, Value: "ef"}, {Type: ItemEOR, Pos: 10},
+			{Type: ItemEOF, Pos: 10}}},
+		{TermRune(';'), "ab;cd;", []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 3},
+			{Type: ItemA, Pos: 3, Value: "cd"}, {Type: ItemEOR, Pos: 6},
+			{Type: ItemEOF, Pos: 6}}},
+		{TermFunc(unicode.IsSpace), "ab \n cd\n", []Item{
+			{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 5},
+			{Type: ItemA, Pos: 5, Value: "cd"}, {Type: ItemEOR, Pos: 8},
+			{Type: ItemEOF, Pos: 8}}},
+	}
+	for _, test := range tests {
+		rec := Record{
+			Buflen:     16,
+			ErrorFn:    SkipRecord,
+			Terminator: test.term,
+			States: []Binding{
+				{ItemA, Letters, true}},
+		}
+		items := lexAll(t, "TestTerminator", test.input, rec)
+		if summarize(items) != summarize(test.expect) {
+			t.Errorf("%q: expected %s, got %s", test.input, summarize(test.expect), summarize(items))
+		}
+	}
+}
+
+func TestSyncTerminator(t *testing.T) {
+	rec := Record{
+		Buflen:     16,
+		Terminator: TermString("\r\n"),
+		States: []Binding{
+			{ItemA, Letters, true}},
+	}
+	rec.ErrorFn = Resync("", rec.Signature(1))
+	items := lexAll(t, "TestSyncTerminator", "ab\r\n1\n2\r\ncd\r\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 4},
+		{Type: ItemError, Pos: 4},
+		{Type: ItemA, Pos: 10, Value: "cd"}, {Type: ItemEOR, Pos: 14},
+		{Type: ItemEOF, Pos: 14}}
+	if summarize(items) != summarize(expect) {
+		t.Errorf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+}
@@ -0,0 +1,296 @@
+package lexrec
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MonthTable maps lower case month names and abbreviations to their
+// month number, 1 through 12.  Tables for other locales may be
+// supplied to Month.
+type MonthTable map[string]int
+
+// EnglishMonths is the default MonthTable, holding the English month
+// names and their three letter abbreviations.
+var EnglishMonths = MonthTable{
+	"january": 1, "jan": 1,
+	"february": 2, "feb": 2,
+	"march": 3, "mar": 3,
+	"april": 4, "apr": 4,
+	"may":  5,
+	"june": 6, "jun": 6,
+	"july": 7, "jul": 7,
+	"august": 8, "aug": 8,
+	"september": 9, "sep": 9, "sept": 9,
+	"october": 10, "oct": 10,
+	"november": 11, "nov": 11,
+	"december": 12, "dec": 12,
+}
+
+// Month returns a StateFn that consumes a run of letters and verifies
+// that, ignoring case, they name a month in table.  If table is nil
+// EnglishMonths is used.  If numeric is true the month is emitted as
+// a two digit number ("01" through "12"), otherwise the name is
+// emitted as it appeared in the input.
+func Month(table MonthTable, numeric bool) StateFn {
+	if table == nil {
+		table = EnglishMonths
+	}
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		c := l.Classifier()
+		for c.IsLetter(l.Peek()) {
+			l.Next()
+		}
+		if l.Size() == 0 {
+			l.unexpected("month name")
+			return false
+		}
+		m, ok := table[strings.ToLower(string(l.Bytes()))]
+		if !ok {
+			l.Errorf("unknown month name %q", l.Bytes())
+			return false
+		}
+		if emit {
+			if numeric {
+				l.EmitValue(t, fmt.Sprintf("%02d", m))
+			} else {
+				l.Emit(t)
+			}
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// WeekdayTable maps lower case weekday names and abbreviations to
+// their time.Weekday.  Tables for other locales may be supplied to
+// Weekday.
+type WeekdayTable map[string]time.Weekday
+
+// EnglishWeekdays is the default WeekdayTable, holding the English
+// weekday names and their three letter abbreviations.
+var EnglishWeekdays = WeekdayTable{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// Weekday returns a StateFn that consumes a run of letters and
+// verifies that, ignoring case, they name a day of the week in table.
+// If table is nil EnglishWeekdays is used.  If numeric is true the
+// day is emitted as its time.Weekday number ("0" for Sunday through
+// "6" for Saturday), otherwise the name is emitted as it appeared in
+// the input.
+func Weekday(table WeekdayTable, numeric bool) StateFn {
+	if table == nil {
+		table = EnglishWeekdays
+	}
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		c := l.Classifier()
+		for c.IsLetter(l.Peek()) {
+			l.Next()
+		}
+		if l.Size() == 0 {
+			l.unexpected("weekday name")
+			return false
+		}
+		d, ok := table[strings.ToLower(string(l.Bytes()))]
+		if !ok {
+			l.Errorf("unknown weekday name %q", l.Bytes())
+			return false
+		}
+		if emit {
+			if numeric {
+				l.EmitValue(t, strconv.Itoa(int(d)))
+			} else {
+				l.Emit(t)
+			}
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// ZoneTable maps timezone abbreviations to their offset from UTC, in
+// seconds.
+type ZoneTable map[string]int
+
+// DefaultZones is the default ZoneTable, holding the abbreviations
+// defined by RFC 5322 along with a handful of other common ones.
+var DefaultZones = ZoneTable{
+	"UT": 0, "UTC": 0, "GMT": 0, "Z": 0,
+	"EST": -5 * 3600, "EDT": -4 * 3600,
+	"CST": -6 * 3600, "CDT": -5 * 3600,
+	"MST": -7 * 3600, "MDT": -6 * 3600,
+	"PST": -8 * 3600, "PDT": -7 * 3600,
+	"AKST": -9 * 3600, "AKDT": -8 * 3600,
+	"HST": -10 * 3600,
+	"WET": 0, "WEST": 1 * 3600,
+	"CET": 1 * 3600, "CEST": 2 * 3600,
+	"EET": 2 * 3600, "EEST": 3 * 3600,
+	"JST":  9 * 3600,
+	"AEST": 10 * 3600, "AEDT": 11 * 3600,
+}
+
+// maxZoneName is the length of the longest name loadZone will try to
+// load; the longest IANA zone identifiers are about 30 bytes.
+const maxZoneName = 64
+
+// zoneCache records the IANA zone names already loaded, as loading a
+// zone reads from disk.  Only names that loaded are recorded, so that
+// the cache is bounded by the size of the zone database no matter
+// what the input holds.
+var zoneCache sync.Map
+
+// loadZone reports whether name is a known IANA zone identifier.
+func loadZone(name string) bool {
+	if !validZoneName(name) {
+		return false
+	}
+	if _, found := zoneCache.Load(name); found {
+		return true
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return false
+	}
+	zoneCache.Store(name, true)
+	return true
+}
+
+// validZoneName reports whether name has the shape of an IANA zone
+// identifier: no longer than maxZoneName, and made up of ASCII
+// letters, digits, and the characters "_+-/".
+func validZoneName(name string) bool {
+	if name == "" || len(name) > maxZoneName {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := rune(name[i])
+		if !isASCIILetter(c) && !isASCIIDigit(c) && strings.IndexRune("_+-/", c) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// NamedZone returns a StateFn that consumes a named timezone.  The
+// name is accepted if it is one of the abbreviations in table, or, if
+// iana is true, if it is an IANA zone identifier such as
+// "America/Los_Angeles" that time.LoadLocation can load.  If table is
+// nil DefaultZones is used.  Abbreviations are matched exactly, they
+// are case sensitive.
+func NamedZone(table ZoneTable, iana bool) StateFn {
+	if table == nil {
+		table = DefaultZones
+	}
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		c := l.Classifier()
+		for {
+			r := l.Peek()
+			if !c.IsLetter(r) && !c.IsDigit(r) && strings.IndexRune("/_-+", r) < 0 {
+				break
+			}
+			l.Next()
+		}
+		if l.Size() == 0 {
+			l.unexpected("timezone name")
+			return false
+		}
+		name := string(l.Bytes())
+		if _, ok := table[name]; !ok && !(iana && name != "Local" && loadZone(name)) {
+			l.Errorf("unknown timezone %q", name)
+			return false
+		}
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// Duration returns a StateFn that consumes a duration, either in the
+// form accepted by time.ParseDuration, e.g., "1.5s" or "200ms", or as
+// a clock reading of [H]H:MM:SS with optional fractional seconds,
+// e.g., "00:05:12".  If unit is zero the duration is emitted as it
+// appeared in the input, otherwise it is emitted as a decimal number
+// of units, e.g., with unit time.Millisecond "1.5s" is emitted as
+// "1500".
+func Duration(unit time.Duration) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		l.AcceptRun("+-0123456789.:hmsuµn")
+		if l.Size() == 0 {
+			l.unexpected("duration")
+			return false
+		}
+		if l.isAlphaNumeric(l.Peek()) {
+			l.Next()
+			l.Errorf("bad duration syntax: %q", l.Bytes())
+			return false
+		}
+		s := string(l.Bytes())
+		var d time.Duration
+		var err error
+		if strings.IndexByte(s, ':') >= 0 {
+			d, err = parseClock(s)
+		} else {
+			d, err = time.ParseDuration(s)
+		}
+		if err != nil {
+			l.Errorf("bad duration syntax: %q", s)
+			return false
+		}
+		if emit {
+			if unit != 0 {
+				l.EmitValue(t, strconv.FormatFloat(float64(d)/float64(unit), 'f', -1, 64))
+			} else {
+				l.Emit(t)
+			}
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// maxClockHours bounds the hours of a clock reading, leaving room for
+// its minutes and seconds within a time.Duration.
+const maxClockHours = uint64(math.MaxInt64/time.Hour) - 1
+
+// parseClock parses a duration of the form [H]H:MM:SS[.fff].
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("expected H:MM:SS, got %q", s)
+	}
+	h, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if h >= maxClockHours {
+		return 0, fmt.Errorf("hours out of range in %q", s)
+	}
+	m, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil || len(parts[1]) != 2 || m > 59 {
+		return 0, fmt.Errorf("bad minutes in %q", s)
+	}
+	if len(parts[2]) < 2 || parts[2][0] < '0' || parts[2][0] > '9' || parts[2][1] < '0' || parts[2][1] > '9' {
+		return 0, fmt.Errorf("bad seconds in %q", s)
+	}
+	sec, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || sec >= 60 || (len(parts[2]) > 2 && parts[2][2] != '.') {
+		return 0, fmt.Errorf("bad seconds in %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second)), nil
+}
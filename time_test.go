@@ -0,0 +1,110 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// timeTest describes the first item expected when lexing input with
+// a Record consisting of fn followed by a newline.
+type timeTest struct {
+	input string
+	typ   ItemType
+	value string
+}
+
+func runTimeTests(t *testing.T, name string, fn StateFn, tests []timeTest) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemEmit, fn, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	for _, test := range tests {
+		items := lexAll(t, name, test.input, rec)
+		if items[0].Type != test.typ {
+			t.Errorf("%s %q: expected type %d, got %q", name, test.input, test.typ, items[0])
+		} else if test.typ == ItemEmit && items[0].Value != test.value {
+			t.Errorf("%s %q: expected %q, got %q", name, test.input, test.value, items[0].Value)
+		}
+	}
+}
+
+func TestMonth(t *testing.T) {
+	runTimeTests(t, "Month", Month(nil, false), []timeTest{
+		{"Oct\n", ItemEmit, "Oct"},
+		{"DECEMBER\n", ItemEmit, "DECEMBER"},
+		{"Foo\n", ItemError, ""},
+		{"1\n", ItemError, ""},
+	})
+	runTimeTests(t, "Month numeric", Month(nil, true), []timeTest{
+		{"Oct\n", ItemEmit, "10"},
+		{"may\n", ItemEmit, "05"},
+	})
+}
+
+func TestWeekday(t *testing.T) {
+	runTimeTests(t, "Weekday", Weekday(nil, false), []timeTest{
+		{"Mon\n", ItemEmit, "Mon"},
+		{"SATURDAY\n", ItemEmit, "SATURDAY"},
+		{"Someday\n", ItemError, ""},
+	})
+	runTimeTests(t, "Weekday numeric", Weekday(nil, true), []timeTest{
+		{"sun\n", ItemEmit, "0"},
+		{"Fri\n", ItemEmit, "5"},
+	})
+}
+
+func TestNamedZone(t *testing.T) {
+	runTimeTests(t, "NamedZone", NamedZone(nil, false), []timeTest{
+		{"UTC\n", ItemEmit, "UTC"},
+		{"PST\n", ItemEmit, "PST"},
+		{"pst\n", ItemError, ""},
+		{"UTC/Nowhere\n", ItemError, ""},
+	})
+	runTimeTests(t, "NamedZone iana", NamedZone(nil, true), []timeTest{
+		{"GMT\n", ItemEmit, "GMT"},
+		{"Not/A_Zone\n", ItemError, ""},
+		{"Local\n", ItemError, ""},
+	})
+}
+
+func TestDuration(t *testing.T) {
+	runTimeTests(t, "Duration", Duration(0), []timeTest{
+		{"1.5s\n", ItemEmit, "1.5s"},
+		{"00:05:12\n", ItemEmit, "00:05:12"},
+		{"5x\n", ItemError, ""},
+		{"00:61:00\n", ItemError, ""},
+		{"1:2\n", ItemError, ""},
+		{"3000000:00:00\n", ItemError, ""},
+	})
+	runTimeTests(t, "Duration ms", Duration(time.Millisecond), []timeTest{
+		{"1.5s\n", ItemEmit, "1500"},
+		{"200ms\n", ItemEmit, "200"},
+		{"0:00:01.25\n", ItemEmit, "1250"},
+		{"1m\n", ItemEmit, "60000"},
+		{"3000000:00:00\n", ItemError, ""},
+	})
+}
+
+func TestLoadZone(t *testing.T) {
+	for _, name := range []string{"", "../../etc/passwd", "Europe/Zürich", strings.Repeat("A", maxZoneName+1)} {
+		if validZoneName(name) {
+			t.Errorf("%q: expected an invalid zone name", name)
+		}
+	}
+	if !loadZone("UTC") {
+		t.Errorf("expected UTC to load")
+	}
+	if _, found := zoneCache.Load("UTC"); !found {
+		t.Errorf("expected UTC to be cached")
+	}
+	if loadZone("Not/A_Zone") {
+		t.Errorf("expected Not/A_Zone not to load")
+	}
+	if _, found := zoneCache.Load("Not/A_Zone"); found {
+		t.Errorf("expected Not/A_Zone not to be cached")
+	}
+}
@@ -0,0 +1,93 @@
+package lexrec
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Bucket holds the records counted by a Timeline in one interval of
+// time.
+type Bucket struct {
+	Start   time.Time // start of the interval, in UTC
+	Records int64     // number of records with a timestamp in the interval
+	Sum     float64   // sum of the Timeline's Sum field over those records
+}
+
+// Timeline counts records per interval of time, e.g., requests per
+// minute, and optionally sums a numeric field, e.g., bytes sent, over
+// each interval.
+type Timeline struct {
+	Width   time.Duration                        // width of each interval
+	Time    func(items []Item) (time.Time, bool) // returns the timestamp of a record, e.g., from TimeLayout
+	Sum     ItemType                             // if > ItemEOF, the field to sum; values that are not numbers are ignored
+	buckets map[time.Time]*Bucket
+}
+
+// TimeLayout returns a function, for use as a Timeline's Time, that
+// parses the value of the item of type t using the time.Parse layout.
+func TimeLayout(t ItemType, layout string) func(items []Item) (time.Time, bool) {
+	return func(items []Item) (time.Time, bool) {
+		for _, item := range items {
+			if item.Type == t {
+				ts, err := time.Parse(layout, item.Value)
+				return ts, err == nil
+			}
+		}
+		return time.Time{}, false
+	}
+}
+
+// Count reads records from l until ItemEOF, adding each successfully
+// lexed record with a timestamp to the bucket for its interval.  Count
+// returns the number of records counted.
+func (tl *Timeline) Count(l *Lexer) (records int64) {
+	if tl.buckets == nil {
+		tl.buckets = make(map[time.Time]*Bucket)
+	}
+	for {
+		items, failed, eof := readRecord(l)
+		if !failed && len(items) > 0 {
+			if ts, ok := tl.Time(items); ok {
+				tl.add(ts, items)
+				records++
+			}
+		}
+		if eof {
+			return
+		}
+	}
+}
+
+// add counts a record with timestamp ts and fields items.
+func (tl *Timeline) add(ts time.Time, items []Item) {
+	start := ts.UTC().Truncate(tl.Width)
+	b, ok := tl.buckets[start]
+	if !ok {
+		b = &Bucket{Start: start}
+		tl.buckets[start] = b
+	}
+	b.Records++
+	if tl.Sum > ItemEOF {
+		for _, item := range items {
+			if item.Type == tl.Sum {
+				if f, err := strconv.ParseFloat(item.Value, 64); err == nil {
+					b.Sum += f
+				}
+			}
+		}
+	}
+}
+
+// Buckets returns the intervals in which records have been counted,
+// in order of time.  Intervals without records are omitted.
+func (tl *Timeline) Buckets() []Bucket {
+	buckets := make([]Bucket, 0, len(tl.buckets))
+	for _, b := range tl.buckets {
+		buckets = append(buckets, *b)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets
+}
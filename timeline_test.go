@@ -0,0 +1,42 @@
+package lexrec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, ExceptRun(" ", true), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, ExceptRun("\n", true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	input := strings.Join([]string{
+		"10:00:05 100",
+		"10:00:59 -",
+		"10:01:00 20",
+		"bogus 7",
+		"10:03:30 1",
+	}, "\n") + "\n"
+	l, err := NewLexer("TestTimeline", strings.NewReader(input), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tl := &Timeline{Width: time.Minute, Time: TimeLayout(ItemA, "15:04:05"), Sum: ItemB}
+	if n := tl.Count(l); n != 4 {
+		t.Errorf("expected 4 records, got %d", n)
+	}
+	minute := func(m int) time.Time {
+		return time.Date(0, 1, 1, 10, m, 0, 0, time.UTC)
+	}
+	expect := []Bucket{{minute(0), 2, 100}, {minute(1), 1, 20}, {minute(3), 1, 1}}
+	if got := tl.Buckets(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+}
@@ -0,0 +1,195 @@
+package lexrec
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// layoutKind identifies an element of a time layout.
+type layoutKind int
+
+const (
+	layoutLiteral layoutKind = iota // text matched exactly
+	layoutWord                      // a run of letters, e.g., "January", "Monday" or "MST"
+	layoutLetters                   // a fixed number of letters, e.g., "Jan" or "PM"
+	layoutDigits                    // a fixed number of digits, e.g., "02" or "2006"
+	layoutNumber                    // 1 to n digits, e.g., "2" or "15"
+	layoutPadded                    // up to n-1 spaces and 1 to n digits, e.g., "_2"
+	layoutSecond                    // like layoutDigits or layoutNumber, with an optional fraction
+	layoutZone                      // a numeric zone offset, e.g., "-07:00", or "Z" if text begins with 'Z'
+	layoutFrac                      // a fraction of a second, of exactly n digits
+	layoutFracOpt                   // an optional fraction of a second, of any number of digits
+)
+
+// layoutElem is an element of a time layout.
+type layoutElem struct {
+	kind layoutKind
+	n    int    // number of letters or digits
+	text string // the element's text in the layout
+}
+
+// layoutChunks lists the elements of a time layout, longest first so
+// that, e.g., "2006" is matched before "2", as the time package does.
+var layoutChunks = []layoutElem{
+	{layoutWord, 0, "January"}, {layoutLetters, 3, "Jan"},
+	{layoutWord, 0, "Monday"}, {layoutLetters, 3, "Mon"}, {layoutWord, 0, "MST"},
+	{layoutDigits, 4, "2006"}, {layoutDigits, 3, "002"},
+	{layoutDigits, 2, "01"}, {layoutDigits, 2, "02"}, {layoutDigits, 2, "03"},
+	{layoutDigits, 2, "04"}, {layoutSecond, 2, "05"}, {layoutDigits, 2, "06"},
+	{layoutNumber, 2, "15"}, {layoutNumber, 2, "1"}, {layoutNumber, 2, "2"},
+	{layoutNumber, 2, "3"}, {layoutNumber, 2, "4"}, {layoutSecond, 1, "5"},
+	{layoutPadded, 3, "__2"}, {layoutPadded, 2, "_2"},
+	{layoutLetters, 2, "PM"}, {layoutLetters, 2, "pm"},
+	{layoutZone, 0, "-07:00:00"}, {layoutZone, 0, "-070000"}, {layoutZone, 0, "-07:00"},
+	{layoutZone, 0, "-0700"}, {layoutZone, 0, "-07"},
+	{layoutZone, 0, "Z07:00:00"}, {layoutZone, 0, "Z070000"}, {layoutZone, 0, "Z07:00"},
+	{layoutZone, 0, "Z0700"}, {layoutZone, 0, "Z07"},
+}
+
+// parseLayout splits a time layout into its elements.
+func parseLayout(layout string) []layoutElem {
+	var elems []layoutElem
+	literal := func(s string) {
+		if n := len(elems); n > 0 && elems[n-1].kind == layoutLiteral {
+			elems[n-1].text += s
+		} else {
+			elems = append(elems, layoutElem{kind: layoutLiteral, text: s})
+		}
+	}
+next:
+	for i := 0; i < len(layout); {
+		if c := layout[i]; (c == '.' || c == ',') && i+1 < len(layout) && (layout[i+1] == '0' || layout[i+1] == '9') {
+			j := i + 1
+			for j < len(layout) && layout[j] == layout[i+1] {
+				j++
+			}
+			if j == len(layout) || layout[j] < '0' || layout[j] > '9' {
+				kind := layoutFrac
+				if layout[i+1] == '9' {
+					kind = layoutFracOpt
+				}
+				elems = append(elems, layoutElem{kind, j - i - 1, layout[i:j]})
+				i = j
+				continue
+			}
+		}
+		for _, e := range layoutChunks {
+			if strings.HasPrefix(layout[i:], e.text) {
+				if e.text == "_2" && strings.HasPrefix(layout[i:], "_2006") {
+					// "_2006" is a literal '_' followed by a year.
+					break
+				}
+				elems = append(elems, e)
+				i += len(e.text)
+				continue next
+			}
+		}
+		literal(layout[i : i+1])
+		i++
+	}
+	return elems
+}
+
+// Timestamp returns a StateFn that consumes exactly the characters the
+// Go time layout would produce, e.g., Timestamp("02/Jan/2006:15:04:05
+// -0700") or Timestamp(time.RFC3339), and emits them as a single item.
+// As time.Parse does, it accepts a fractional second after the
+// seconds even if the layout has none.  The span is verified with
+// time.Parse, so that, e.g., "31/Feb/2000" is an error.
+func Timestamp(layout string) StateFn {
+	elems := parseLayout(layout)
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		for i, e := range elems {
+			if !l.layoutElem(e, i+1 < len(elems) && (elems[i+1].kind == layoutFrac || elems[i+1].kind == layoutFracOpt)) {
+				l.Errorf("timestamp %q does not match layout %q", l.Bytes(), layout)
+				return false
+			}
+		}
+		if _, err := time.Parse(layout, string(l.Bytes())); err != nil {
+			l.Errorf("bad timestamp %q: %v", l.Bytes(), err)
+			return false
+		}
+		if emit {
+			l.Emit(t)
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// layoutElem consumes the input matching e, returning false if it
+// does not match.  If frac is true the layout has a fraction after e.
+func (l *Lexer) layoutElem(e layoutElem, frac bool) bool {
+	switch e.kind {
+	case layoutLiteral:
+		return l.acceptString(e.text)
+	case layoutWord:
+		return l.acceptN(isASCIILetter, 1, math.MaxInt)
+	case layoutLetters:
+		return l.acceptN(isASCIILetter, e.n, e.n)
+	case layoutDigits:
+		return l.acceptN(isASCIIDigit, e.n, e.n)
+	case layoutNumber:
+		return l.acceptN(isASCIIDigit, 1, e.n)
+	case layoutPadded:
+		for i := 1; i < e.n && l.Peek() == ' '; i++ {
+			l.Next()
+		}
+		return l.acceptN(isASCIIDigit, 1, e.n)
+	case layoutSecond:
+		if !l.acceptN(isASCIIDigit, 1, e.n) {
+			return false
+		}
+		if !frac {
+			l.acceptFrac(0)
+		}
+		return true
+	case layoutZone:
+		if e.text[0] == 'Z' && l.Accept("Z") {
+			return true
+		}
+		if !l.Accept("+-") {
+			return false
+		}
+		for _, c := range e.text[1:] {
+			if c == ':' && !l.Accept(":") || c != ':' && !l.acceptN(isASCIIDigit, 1, 1) {
+				return false
+			}
+		}
+		return true
+	case layoutFrac:
+		return l.acceptFrac(e.n)
+	case layoutFracOpt:
+		l.acceptFrac(0)
+		return true
+	}
+	return false
+}
+
+// acceptFrac consumes a '.' or ',' followed by n digits, or if n is 0
+// one or more digits, returning false, and consuming nothing, if there
+// is no such fraction.
+func (l *Lexer) acceptFrac(n int) bool {
+	min, max := n, n
+	if n == 0 {
+		min, max = 1, math.MaxInt
+	}
+	pos, rpos, width, eof := l.pos, l.rpos, l.width, l.eof
+	if l.Accept(".,") && l.acceptN(isASCIIDigit, min, max) {
+		return true
+	}
+	l.pos, l.rpos, l.width, l.eof = pos, rpos, width, eof
+	return false
+}
+
+// acceptN consumes at least min and at most max runes for which fn
+// returns true, returning false if there are fewer than min.
+func (l *Lexer) acceptN(fn func(r rune) bool, min, max int) bool {
+	n := 0
+	for n < max && l.AcceptFunc(fn) {
+		n++
+	}
+	return n >= min
+}
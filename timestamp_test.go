@@ -0,0 +1,69 @@
+package lexrec
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimestamp(t *testing.T) {
+	tests := []struct {
+		layout string
+		input  string
+		ok     bool
+	}{
+		{"02/Jan/2006:15:04:05 -0700", "10/Oct/2000:13:55:36 -0700", true},
+		{"02/Jan/2006:15:04:05 -0700", "10/Oct/2000:13:55:36.25 -0700", true},
+		{"02/Jan/2006:15:04:05 -0700", "31/Feb/2000:13:55:36 -0700", false},
+		{"02/Jan/2006:15:04:05 -0700", "1/Oct/2000:13:55:36 -0700", false},
+		{"02/Jan/2006:15:04:05 -0700", "10/Oct/2000:13:55:36 -07:00", false},
+		{time.RFC3339, "2024-03-01T12:34:56Z", true},
+		{time.RFC3339, "2024-03-01T12:34:56.789+05:30", true},
+		{time.RFC3339Nano, "2024-03-01T12:34:56-08:00", true},
+		{time.Stamp, "Mar  1 09:05:03", true},
+		{time.Stamp, "Mar 11 09:05:03", true},
+		{time.ANSIC, "Fri Mar  1 09:05:03 2024", true},
+		{time.RFC850, "Friday, 01-Mar-24 12:00:00 UTC", true},
+		{time.Kitchen, "3:04PM", true},
+		{"15:04:05.000", "09:05:03.123", true},
+		{"15:04:05.000", "09:05:03.12", false},
+		{"2006_01_02", "2024_03_01", true},
+	}
+	for _, test := range tests {
+		rec := Record{
+			Buflen:  8,
+			ErrorFn: SkipPast("\n"),
+			States: []Binding{
+				{ItemA, Timestamp(test.layout), true},
+				{ItemIgnore, Accept(" ", true), false},
+				{ItemB, Letters, true},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		items := lexAll(t, "TestTimestamp", test.input+" ab\n", rec)
+		switch {
+		case test.ok && (items[0].Type != ItemA || items[0].Value != test.input || items[1].Value != "ab"):
+			t.Errorf("%q: expected %q, got %v", test.layout, test.input, items)
+		case !test.ok && items[0].Type != ItemError:
+			t.Errorf("%q: expected an error for %q, got %v", test.layout, test.input, items)
+		}
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	elems := parseLayout("_2006 Jan _2 15:04:05.999 MST")
+	var kinds []layoutKind
+	for _, e := range elems {
+		kinds = append(kinds, e.kind)
+	}
+	expect := []layoutKind{
+		layoutLiteral, layoutDigits, layoutLiteral, layoutLetters, layoutLiteral, layoutPadded,
+		layoutLiteral, layoutNumber, layoutLiteral, layoutDigits, layoutLiteral, layoutSecond,
+		layoutFracOpt, layoutLiteral, layoutWord}
+	if len(kinds) != len(expect) {
+		t.Fatalf("expected %v, got %v", expect, kinds)
+	}
+	for i := range expect {
+		if kinds[i] != expect[i] {
+			t.Errorf("element %d (%q): expected kind %d, got %d", i, elems[i].text, expect[i], kinds[i])
+		}
+	}
+}
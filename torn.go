@@ -0,0 +1,36 @@
+package lexrec
+
+// tear looks for a torn record, one that was interrupted mid-line by
+// the start of another record written concurrently, when the current
+// record has failed.  It searches the line, from just after the start
+// of the record, for a position at which the StateFns of the Record's
+// Torn signature all succeed.  If one is found, the fragment of the
+// torn record before it is emitted as an ItemTorn, and lexing resumes
+// at the start of the interrupting record.  Otherwise the Lexer is
+// left where it was and tear returns false.
+func (l *Lexer) tear() bool {
+	back := l.rpos - l.recPos
+	if back > int64(l.pos) {
+		// the start of the record is no longer buffered.
+		return false
+	}
+	term := l.rec.Terminator
+	if term == nil {
+		term = newline
+	}
+	pos, start, rpos, width, eof := l.pos, l.start, l.rpos, l.width, l.eof
+	l.pos -= int(back)
+	l.rpos, l.start = l.recPos, l.pos
+	for l.Next() != EOF {
+		if term(l) {
+			break
+		}
+		if l.try(l.rec.Torn) {
+			l.send(Item{Type: ItemTorn, Pos: l.rpos, Value: string(l.buf[l.start:l.pos])})
+			l.Skip()
+			return true
+		}
+	}
+	l.pos, l.start, l.rpos, l.width, l.eof = pos, start, rpos, width, eof
+	return false
+}
@@ -0,0 +1,35 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestTorn(t *testing.T) {
+	rec := Record{
+		Buflen:  8,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemIgnore, Accept("<", true), false},
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(">", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	rec.Torn = rec.Signature(3)
+	items := lexAll(t, "TestTorn", "<ab>1\n<cd<ef>2\n>3\n<gh>4\n", rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 1, Value: "ab"}, {Type: ItemB, Pos: 4, Value: "1"}, {Type: ItemEOR, Pos: 6},
+		{Type: ItemA, Pos: 7, Value: "cd"}, {Type: ItemError, Pos: 9},
+		{Type: ItemTorn, Pos: 9, Value: "<cd"}, {Type: ItemA, Pos: 10, Value: "ef"}, {Type: ItemB, Pos: 13, Value: "2"}, {Type: ItemEOR, Pos: 15},
+		{Type: ItemError, Pos: 15},
+		{Type: ItemA, Pos: 19, Value: "gh"}, {Type: ItemB, Pos: 22, Value: "4"}, {Type: ItemEOR, Pos: 24},
+		{Type: ItemEOF, Pos: 24}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range items {
+		if items[i].Type != ItemError && items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package lexrec
+
+// Format is one of the record formats recognized by a Triage.
+type Format struct {
+	Name   string    // name of the format, reported by Counts and in emitted items
+	States []Binding // lexer states that make up a record in this format
+}
+
+// Triage lexes records that may each be in any of several formats, so
+// that the formats actually present in an input can be found in a
+// single pass.  Use its Lex method as the StateFn of a Record's only
+// Binding, e.g.:
+//
+//	triage := &lexrec.Triage{Formats: []lexrec.Format{{"ncsa", ncsa}, {"syslog", syslog}}}
+//	rec.States = []lexrec.Binding{{ItemFormat, triage.Lex, true}}
+//
+// Each record is lexed by the first of the Formats whose States all
+// succeed against it.  The States of each Format should consume the
+// whole record, including its terminator, so that a Format matching
+// only the start of a record is not chosen in place of a later one.
+// The counts are updated by the Lexer's goroutine, and are only safe
+// to read once ItemEOF has been received.
+type Triage struct {
+	Formats   []Format // candidate formats, tried in order
+	Matched   []int64  // records lexed by each format, indexed by position in Formats
+	Unmatched int64    // records that matched none of the formats
+}
+
+// Lex is a StateFn that lexes a record using the first of the
+// Triage's Formats that matches it.  If emit is true, an item of type
+// t holding the name of the Format precedes the record's items.  If no
+// Format matches, an error is emitted.
+func (tr *Triage) Lex(l *Lexer, t ItemType, emit bool) bool {
+	for i, f := range tr.Formats {
+		if !l.try(f.States) {
+			continue
+		}
+		for len(tr.Matched) <= i {
+			tr.Matched = append(tr.Matched, 0)
+		}
+		tr.Matched[i]++
+		if emit {
+			l.EmitValue(t, f.Name)
+		}
+		for _, b := range f.States {
+			if !b.StateFn(l, b.ItemType, b.Emit) {
+				return false
+			}
+		}
+		return true
+	}
+	tr.Unmatched++
+	l.Errorf("record matches none of %d formats, got %q", len(tr.Formats), l.Peek())
+	return false
+}
+
+// Counts returns the number of records lexed by each Format, keyed by
+// its name.  Records that matched none of the Formats are counted
+// under the empty name.
+func (tr *Triage) Counts() map[string]int64 {
+	counts := make(map[string]int64, len(tr.Formats)+1)
+	for i, f := range tr.Formats {
+		var n int64
+		if i < len(tr.Matched) {
+			n = tr.Matched[i]
+		}
+		counts[f.Name] += n
+	}
+	if tr.Unmatched > 0 {
+		counts[""] = tr.Unmatched
+	}
+	return counts
+}
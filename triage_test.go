@@ -0,0 +1,34 @@
+package lexrec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTriage(t *testing.T) {
+	newline := Binding{ItemIgnore, Accept("\n", true), false}
+	triage := &Triage{Formats: []Format{
+		{"letters", []Binding{{ItemA, Letters, true}, newline}},
+		{"digits", []Binding{{ItemB, Digits, true}, newline}},
+	}}
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemEmit, triage.Lex, true}},
+	}
+	items := lexAll(t, "TestTriage", "ab\n12\n!\ncd\n", rec)
+	expect := []Item{
+		{Type: ItemEmit, Pos: 0, Value: "letters"}, {Type: ItemA, Pos: 0, Value: "ab"}, {Type: ItemEOR, Pos: 3},
+		{Type: ItemEmit, Pos: 3, Value: "digits"}, {Type: ItemB, Pos: 3, Value: "12"}, {Type: ItemEOR, Pos: 6},
+		{Type: ItemError, Pos: 6},
+		{Type: ItemEmit, Pos: 8, Value: "letters"}, {Type: ItemA, Pos: 8, Value: "cd"}, {Type: ItemEOR, Pos: 11},
+		{Type: ItemEOF, Pos: 11}}
+	if summarize(items) != summarize(expect) {
+		t.Errorf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	counts := map[string]int64{"letters": 2, "digits": 1, "": 1}
+	if got := triage.Counts(); !reflect.DeepEqual(got, counts) {
+		t.Errorf("expected counts %v, got %v", counts, got)
+	}
+}
@@ -0,0 +1,102 @@
+package lexrec
+
+import (
+	"io"
+)
+
+// Tuning holds the observations made by Tune over a sample of input,
+// and the settings it suggests for the Record.
+type Tuning struct {
+	Records       int64     // records lexed, including failed records
+	Failures      int64     // records that failed to lex
+	MeanRecordLen int64     // mean record length, in bytes
+	MaxRecordLen  int64     // longest record, in bytes
+	MaxTokenSize  int64     // longest token consumed by a single StateFn, in bytes
+	Buflen        int       // suggested Buflen
+	HotFailures   []int     // indices of Bindings failing at least 10% of the time
+	Coverage      *Coverage // per-Binding outcome counts
+}
+
+// Tune runs rec over a sample of input read from r and suggests
+// settings for it.  The suggested Buflen is the smallest power of two
+// no less than 512, four times the mean record length, and the
+// longest token seen, so that a typical read holds several records.
+// Bindings that fail on at least 10% of their calls are reported in
+// HotFailures.  Callers tuning against a large corpus should limit r,
+// e.g., with io.LimitReader.
+func Tune(r io.Reader, rec Record) (*Tuning, error) {
+	t := &Tuning{Coverage: &Coverage{}}
+	var total int64
+	rec.Coverage = t.Coverage
+	onSpan := rec.OnSpan
+	rec.OnSpan = func(s Span) {
+		t.Records++
+		total += s.Len
+		if s.Err {
+			t.Failures++
+		}
+		if s.Len > t.MaxRecordLen {
+			t.MaxRecordLen = s.Len
+		}
+		if onSpan != nil {
+			onSpan(s)
+		}
+	}
+	states := make([]Binding, len(rec.States))
+	for i, b := range rec.States {
+		fn := b.StateFn
+		b.StateFn = func(l *Lexer, it ItemType, emit bool) bool {
+			from := l.tokenPos()
+			success := fn(l, it, emit)
+			if n := l.rpos - from; n > t.MaxTokenSize {
+				t.MaxTokenSize = n
+			}
+			return success
+		}
+		states[i] = b
+	}
+	rec.States = states
+
+	l, err := NewLexer("Tune", r, rec)
+	if err != nil {
+		return nil, err
+	}
+	l.Drain()
+
+	if t.Records > 0 {
+		t.MeanRecordLen = total / t.Records
+	}
+	t.Buflen = suggestBuflen(t.MeanRecordLen, t.MaxTokenSize)
+	for i, s := range t.Coverage.States {
+		if calls := s.Success + s.Failure; calls > 0 && s.Failure*10 >= calls {
+			t.HotFailures = append(t.HotFailures, i)
+		}
+	}
+	return t, nil
+}
+
+// suggestBuflen returns the smallest power of two no less than 512,
+// four times the mean record length, and the longest token.
+func suggestBuflen(mean, maxToken int64) int {
+	want := 4 * mean
+	if maxToken > want {
+		want = maxToken
+	}
+	n := 512
+	for int64(n) < want {
+		n *= 2
+	}
+	return n
+}
+
+// autoBuflen resizes the Lexer's read buffer once the number of
+// records given by the Record's AutoBuflen have been lexed, using the
+// mean length of those records.
+func (l *Lexer) autoBuflen() {
+	if l.rec.AutoBuflen < 1 || l.nrec != int64(l.rec.AutoBuflen) {
+		return
+	}
+	if n := suggestBuflen(l.tokenPos()/l.nrec, 0); n != len(l.next) {
+		l.next = make([]byte, n)
+	}
+}
@@ -0,0 +1,67 @@
+package lexrec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTune(t *testing.T) {
+	rec := Record{
+		Buflen:  1,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	input := strings.Repeat("abc 123\n", 9) + strings.Repeat("x", 1000) + " y\n"
+	tuning, err := Tune(strings.NewReader(input), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tuning.Records != 10 || tuning.Failures != 1 {
+		t.Errorf("expected 10 records and 1 failure, got %d and %d", tuning.Records, tuning.Failures)
+	}
+	if tuning.MaxRecordLen != 1003 {
+		t.Errorf("expected MaxRecordLen 1003, got %d", tuning.MaxRecordLen)
+	}
+	if tuning.MaxTokenSize != 1000 {
+		t.Errorf("expected MaxTokenSize 1000, got %d", tuning.MaxTokenSize)
+	}
+	if tuning.Buflen != 1024 {
+		t.Errorf("expected Buflen 1024, got %d", tuning.Buflen)
+	}
+	if expect := []int{2}; !reflect.DeepEqual(tuning.HotFailures, expect) {
+		t.Errorf("expected HotFailures %v, got %v", expect, tuning.HotFailures)
+	}
+}
+
+func TestAutoBuflen(t *testing.T) {
+	rec := Record{
+		Buflen:     1,
+		AutoBuflen: 2,
+		ErrorFn:    SkipPast("\n"),
+		States: []Binding{
+			{ItemA, ExceptRun("\n", true), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	line := strings.Repeat("a", 299) + "\n"
+	l, err := NewLexer("TestAutoBuflen", strings.NewReader(strings.Repeat(line, 3)), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		l.NextItem()
+	}
+	// the second record's EOR has been received, the third
+	// record's first item can only be sent after autoBuflen.
+	if item := l.NextItem(); item.Type != ItemA {
+		t.Fatalf("expected ItemA, got %q", item)
+	}
+	if n := len(l.next); n != 2048 {
+		t.Errorf("expected Buflen 2048, got %d", n)
+	}
+	l.Drain()
+}
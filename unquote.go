@@ -0,0 +1,71 @@
+package lexrec
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Unquote returns a StateFn that runs fn, which consumes a quoted
+// field, e.g., Quote or one returned by Delimited, and emits the
+// field's decoded value rather than its source: the opening and
+// closing delimiters are stripped, a doubled closing delimiter stands
+// for one, and the backslash escapes \n, \r, \t, \0 and \\ are
+// resolved, with a backslash before any other rune standing for that
+// rune.  The item's position is still that of the opening delimiter.
+// If fn fails, its error is emitted as usual.
+func Unquote(fn StateFn) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		start := l.start
+		keep := l.keep
+		l.keep = true
+		ok := fn(l, t, false)
+		l.keep = keep
+		if !ok {
+			return false
+		}
+		l.start = start
+		if emit {
+			l.EmitValue(t, unquote(l.buf[start:l.pos]))
+		} else {
+			l.Skip()
+		}
+		return true
+	}
+}
+
+// unquote returns the decoded value of the quoted field b.
+func unquote(b []byte) string {
+	_, n := utf8.DecodeRune(b)
+	close, m := utf8.DecodeLastRune(b)
+	if len(b) < n+m {
+		return string(b)
+	}
+	s := string(b[n : len(b)-m])
+	if !strings.ContainsRune(s, '\\') && !strings.ContainsRune(s, close) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, w := utf8.DecodeRuneInString(s[i:])
+		i += w
+		switch {
+		case r == '\\' && i < len(s):
+			r, w = utf8.DecodeRuneInString(s[i:])
+			i += w
+			switch r {
+			case 'n':
+				r = '\n'
+			case 'r':
+				r = '\r'
+			case 't':
+				r = '\t'
+			case '0':
+				r = 0
+			}
+		case r == close && strings.HasPrefix(s[i:], string(close)):
+			i += w
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,49 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestUnquote(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Unquote(Quote), true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Unquote(Delimited('[', ']', '\\', false)), true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	input := `"a \"b\"\tc\\" [x\]y]` + "\n" + `"plain" []` + "\n" + `"bad [x]` + "\n"
+	items := lexAll(t, "TestUnquote", input, rec)
+	expect := []Item{
+		{Type: ItemA, Pos: 0, Value: "a \"b\"\tc\\"}, {Type: ItemB, Pos: 15, Value: "x]y"}, {Type: ItemEOR, Pos: 22},
+		{Type: ItemA, Pos: 22, Value: "plain"}, {Type: ItemB, Pos: 30}, {Type: ItemEOR, Pos: 33},
+		{Type: ItemError, Pos: 42},
+		{Type: ItemEOF, Pos: 42}}
+	if summarize(items) != summarize(expect) {
+		t.Fatalf("expected %s, got %s", summarize(expect), summarize(items))
+	}
+	for i := range items {
+		if items[i].Pos != expect[i].Pos {
+			t.Errorf("expected %v at %d, got %d", items[i], expect[i].Pos, items[i].Pos)
+		}
+	}
+}
+
+func TestUnquoteDoubled(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{`'it''s'`, "it's"},
+		{`"a""b""c"`, `a"b"c`},
+		{`"\x\0"`, "x\x00"},
+		{`""`, ""},
+		{`"`, `"`},
+	}
+	for _, test := range tests {
+		if got := unquote([]byte(test.in)); got != test.out {
+			t.Errorf("%s: expected %q, got %q", test.in, test.out, got)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package lexrec
+
+import (
+	"fmt"
+)
+
+// Until consumes the input up to, but not including, the next
+// occurrence of the exact, non-empty, sequence of bytes delim,
+// returning true if at least one byte was consumed.  The search stops
+// at the end of the record, as defined by the Record's Terminator or,
+// if it has none, a newline, so that a missing delimiter does not
+// read the rest of the input.  If delim is not found before the end
+// of the record or of the input, nothing is consumed.
+func (l *Lexer) Until(delim string) bool {
+	term := l.rec.Terminator
+	if term == nil {
+		term = newline
+	}
+	from, fromRpos, fromWidth, eof := l.pos, l.rpos, l.width, l.eof
+	for {
+		pos, rpos, width := l.pos, l.rpos, l.width
+		if l.acceptString(delim) {
+			l.pos, l.rpos, l.width = pos, rpos, width
+			break
+		}
+		if term(l) || l.nextByte() == EOF {
+			l.pos, l.rpos, l.width, l.eof = from, fromRpos, fromWidth, eof
+			return false
+		}
+	}
+	return l.pos > from
+}
+
+// Until returns a StateFn that consumes a field running up to, but not
+// including, the multi-byte delimiter delim, e.g., Until("] ") or
+// Until(" | "), for formats whose fields are separated by a sequence
+// rather than a single character.  If delim is not found before the
+// end of the record, or the field is empty, nothing is consumed and
+// an error is emitted.
+func Until(delim string) StateFn {
+	return funcState(func(l *Lexer) bool { return l.Until(delim) }, true,
+		fmt.Sprintf("a run of characters ending in %q", delim))
+}
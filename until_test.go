@@ -0,0 +1,58 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestUntil(t *testing.T) {
+	rec := Record{
+		Buflen:  4,
+		ErrorFn: SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Until(" | "), true},
+			{ItemIgnore, Literal(" | "), false},
+			{ItemB, Until("]\n"), true},
+			{ItemIgnore, Literal("]\n"), false}},
+	}
+	items := lexAll(t, "TestUntil", "a b|c | [x] y]\n | z]\nd | é]\ne | f", rec)
+	expect := summarize([]Item{
+		{Type: ItemA, Pos: 0, Value: "a b|c"}, {Type: ItemB, Pos: 8, Value: "[x] y"}, {Type: ItemEOR, Pos: 15},
+		{Type: ItemError, Pos: 15},
+		{Type: ItemA, Pos: 21, Value: "d"}, {Type: ItemB, Pos: 25, Value: "é"}, {Type: ItemEOR, Pos: 29},
+		{Type: ItemA, Pos: 29, Value: "e"}, {Type: ItemError, Pos: 33},
+		{Type: ItemEOF, Pos: 34}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+	for _, item := range items {
+		if item.Type == ItemError && item.Pos != 15 && item.Pos != 33 {
+			t.Errorf("expected the error at the start of the field, got %v", item)
+		}
+	}
+	if err := CheckStateFn(Until(" | "), GenChoice("ab | ", "é | ", "a|b | "), 10, 1); err != nil {
+		t.Errorf("unexpected violation: %v", err)
+	}
+}
+
+func TestUntilTerminator(t *testing.T) {
+	rec := Record{
+		Buflen:     4,
+		ErrorFn:    SkipRecord,
+		Terminator: TermString(";"),
+		States: []Binding{
+			{ItemA, Until(" | "), true},
+			{ItemIgnore, Literal(" | "), false},
+			{ItemB, Letters, true}},
+	}
+	items := lexAll(t, "TestUntilTerminator", "ab;c | d;", rec)
+	expect := summarize([]Item{
+		{Type: ItemError, Pos: 0},
+		{Type: ItemA, Pos: 3, Value: "c"}, {Type: ItemB, Pos: 7, Value: "d"}, {Type: ItemEOR, Pos: 9},
+		{Type: ItemEOF, Pos: 9}})
+	if got := summarize(items); got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+	if items[0].Type == ItemError && items[0].Pos != 0 {
+		t.Errorf("expected the error at the start of the field, got %v", items[0])
+	}
+}
@@ -0,0 +1,58 @@
+package lexrec
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// URI returns a StateFn that consumes an absolute or relative URI,
+// e.g., "https://example.com/a%20b?q=1" or "/index.html", running up
+// to whitespace, a control character, a rune in delims, e.g., `"`, or
+// the end of the input.  A percent-escape is consumed as a unit, and
+// one not followed by two hex digits is reported with an
+// *InvalidEscapeError holding its exact position.  If decode is true
+// the item's value is the percent-decoded form of the URI, otherwise
+// it is emitted as it appeared in the input.
+func URI(delims string, decode bool) StateFn {
+	return func(l *Lexer, t ItemType, emit bool) bool {
+		escaped := false
+		for {
+			r := l.Next()
+			if r == EOF || unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(delims, r) {
+				l.Backup()
+				break
+			}
+			if r == '%' {
+				pos, from := l.rpos-1, l.pos-1
+				if !l.acceptN(isHexDigit, 2, 2) {
+					l.Fail(&InvalidEscapeError{Pos: pos, Escape: string(l.buf[from:l.pos])})
+					return false
+				}
+				escaped = true
+			}
+		}
+		if l.Size() == 0 {
+			l.unexpected("URI")
+			return false
+		}
+		if !emit {
+			l.Skip()
+			return true
+		}
+		if decode && escaped {
+			// every escape has been validated, so unescaping
+			// cannot fail.
+			s, _ := url.PathUnescape(string(l.Bytes()))
+			l.EmitValue(t, s)
+		} else {
+			l.Emit(t)
+		}
+		return true
+	}
+}
+
+// isHexDigit reports whether r is an ASCII hex digit.
+func isHexDigit(r rune) bool {
+	return strings.ContainsRune(hexDigits, r)
+}
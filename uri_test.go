@@ -0,0 +1,51 @@
+package lexrec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestURI(t *testing.T) {
+	for _, decode := range []bool{false, true} {
+		rec := Record{
+			Buflen:  8,
+			ErrorFn: SkipPast("\n"),
+			States: []Binding{
+				{ItemIgnore, Accept(`"`, true), false},
+				{ItemA, URI(`"`, decode), true},
+				{ItemIgnore, Accept(`"`, true), false},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		tests := []struct {
+			input, raw, decoded string
+		}{
+			{"https://example.com/a%20b?q=1", "https://example.com/a%20b?q=1", "https://example.com/a b?q=1"},
+			{"/index.html", "/index.html", "/index.html"},
+			{"/caf%C3%A9/%2f", "/caf%C3%A9/%2f", "/café//"},
+			{"/é+1", "/é+1", "/é+1"},
+		}
+		for _, test := range tests {
+			items := lexAll(t, "TestURI", `"`+test.input+`"`+"\n", rec)
+			want := test.raw
+			if decode {
+				want = test.decoded
+			}
+			if items[0].Type != ItemA || items[0].Value != want {
+				t.Errorf("%q: expected %q, got %v", test.input, want, items[0])
+			}
+		}
+		items := lexAll(t, "TestURI", `"/a%2g"`+"\n"+`"/a b"`+"\n"+`""`+"\n", rec)
+		var ie *InvalidEscapeError
+		if !errors.As(items[0].Err.Cause, &ie) || ie.Pos != 3 || ie.Escape != "%2" {
+			t.Errorf("expected an *InvalidEscapeError for %%2 at 3, got %v", items[0])
+		}
+		expect := summarize([]Item{
+			{Type: ItemError, Pos: 0},
+			{Type: ItemA, Pos: 9, Value: "/a"}, {Type: ItemError, Pos: 11},
+			{Type: ItemError, Pos: 16},
+			{Type: ItemEOF, Pos: 18}})
+		if got := summarize(items); got != expect {
+			t.Errorf("expected %s, got %s", expect, got)
+		}
+	}
+}
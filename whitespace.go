@@ -0,0 +1,57 @@
+package lexrec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Whitespace is a policy for the whitespace that separates fields,
+// applied by the Sep StateFn.  Set a Record's Whitespace to a
+// *Whitespace to apply it to every Sep binding of the Record, rather
+// than encoding a policy in each of them.
+type Whitespace struct {
+	Run  bool // if true, one or more separators are allowed; otherwise exactly one
+	Tabs bool // if true, tabs as well as spaces are separators
+	Warn bool // if true, violations are reported with an ItemWarning and accepted; otherwise they are errors
+}
+
+// Sep is a StateFn that consumes the whitespace between fields,
+// according to the Record's Whitespace policy.  A Record without one
+// accepts any run of spaces and tabs.  Whatever the policy, at least
+// one space or tab is required.
+func Sep(l *Lexer, t ItemType, emit bool) (success bool) {
+	start := l.tokenPos()
+	if !l.AcceptRun(" \t") {
+		l.unexpected("whitespace")
+		return false
+	}
+	if p := l.rec.Whitespace; p != nil {
+		if msg := p.violation(string(l.Bytes())); msg != "" {
+			if !p.Warn {
+				l.Errorf("%s", msg)
+				return false
+			}
+			if l.trial == 0 {
+				l.send(Item{Type: ItemWarning, Pos: start, Value: msg})
+			}
+		}
+	}
+	if emit {
+		l.Emit(t)
+	} else {
+		l.Skip()
+	}
+	return true
+}
+
+// violation describes how the separator sep violates the policy, or
+// returns "" if it does not.
+func (p *Whitespace) violation(sep string) string {
+	switch {
+	case !p.Tabs && strings.IndexByte(sep, '\t') >= 0:
+		return fmt.Sprintf("separator %q contains a tab", sep)
+	case !p.Run && len(sep) > 1:
+		return fmt.Sprintf("separator %q is %d characters, expected 1", sep, len(sep))
+	}
+	return ""
+}
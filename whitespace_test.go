@@ -0,0 +1,54 @@
+package lexrec
+
+import (
+	"testing"
+)
+
+func TestWhitespace(t *testing.T) {
+	input := "a b\na  b\na\tb\nab\n"
+	tests := []struct {
+		policy *Whitespace
+		expect string
+	}{
+		{nil, summarize([]Item{
+			{Type: ItemA, Pos: 0, Value: "a"}, {Type: ItemB, Pos: 2, Value: "b"}, {Type: ItemEOR, Pos: 4},
+			{Type: ItemA, Pos: 4, Value: "a"}, {Type: ItemB, Pos: 7, Value: "b"}, {Type: ItemEOR, Pos: 9},
+			{Type: ItemA, Pos: 9, Value: "a"}, {Type: ItemB, Pos: 11, Value: "b"}, {Type: ItemEOR, Pos: 13},
+			{Type: ItemA, Pos: 13, Value: "ab"}, {Type: ItemError, Pos: 15},
+			{Type: ItemEOF, Pos: 16}})},
+		{&Whitespace{}, summarize([]Item{
+			{Type: ItemA, Pos: 0, Value: "a"}, {Type: ItemB, Pos: 2, Value: "b"}, {Type: ItemEOR, Pos: 4},
+			{Type: ItemA, Pos: 4, Value: "a"}, {Type: ItemError, Pos: 7},
+			{Type: ItemA, Pos: 9, Value: "a"}, {Type: ItemError, Pos: 11},
+			{Type: ItemA, Pos: 13, Value: "ab"}, {Type: ItemError, Pos: 15},
+			{Type: ItemEOF, Pos: 16}})},
+		{&Whitespace{Run: true, Tabs: true}, summarize([]Item{
+			{Type: ItemA, Pos: 0, Value: "a"}, {Type: ItemB, Pos: 2, Value: "b"}, {Type: ItemEOR, Pos: 4},
+			{Type: ItemA, Pos: 4, Value: "a"}, {Type: ItemB, Pos: 7, Value: "b"}, {Type: ItemEOR, Pos: 9},
+			{Type: ItemA, Pos: 9, Value: "a"}, {Type: ItemB, Pos: 11, Value: "b"}, {Type: ItemEOR, Pos: 13},
+			{Type: ItemA, Pos: 13, Value: "ab"}, {Type: ItemError, Pos: 15},
+			{Type: ItemEOF, Pos: 16}})},
+		{&Whitespace{Warn: true}, summarize([]Item{
+			{Type: ItemA, Pos: 0, Value: "a"}, {Type: ItemB, Pos: 2, Value: "b"}, {Type: ItemEOR, Pos: 4},
+			{Type: ItemA, Pos: 4, Value: "a"}, {Type: ItemWarning, Pos: 5, Value: `separator "  " is 2 characters, expected 1`}, {Type: ItemB, Pos: 7, Value: "b"}, {Type: ItemEOR, Pos: 9},
+			{Type: ItemA, Pos: 9, Value: "a"}, {Type: ItemWarning, Pos: 10, Value: `separator "\t" contains a tab`}, {Type: ItemB, Pos: 11, Value: "b"}, {Type: ItemEOR, Pos: 13},
+			{Type: ItemA, Pos: 13, Value: "ab"}, {Type: ItemError, Pos: 15},
+			{Type: ItemEOF, Pos: 16}})},
+	}
+	for i, test := range tests {
+		rec := Record{
+			Buflen:     16,
+			ErrorFn:    SkipPast("\n"),
+			Whitespace: test.policy,
+			States: []Binding{
+				{ItemA, Letters, true},
+				{ItemIgnore, Sep, false},
+				{ItemB, Letters, true},
+				{ItemIgnore, Accept("\n", true), false}},
+		}
+		items := lexAll(t, "TestWhitespace", input, rec)
+		if got := summarize(items); got != test.expect {
+			t.Errorf("%d: expected %s, got %s", i, test.expect, got)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package lexrec
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// The wire format of an Item stream begins with a header: the magic
+// bytes "LXRS", the format version, and the names of the stream's
+// item types, as a count followed by pairs of type and name.  Each
+// item follows as its type, the difference between its position and
+// that of the previous item, and its value, with, for an ItemError,
+// the State and Binding of its Err.  Integers are varints, and
+// strings a uvarint length followed by their bytes.
+const (
+	wireMagic   = "LXRS"
+	wireVersion = 1
+)
+
+// ItemEncoder writes Items in a compact, versioned binary format, so
+// that lexing can run in one process and the consumption of the items
+// in another, which reads them with an ItemDecoder.  The Err.Cause of
+// an ItemError is not written.
+type ItemEncoder struct {
+	w   *bufio.Writer
+	buf []byte
+	pos int64
+	err error
+}
+
+// NewItemEncoder returns an ItemEncoder that writes to w, beginning
+// with a header naming the item types in names, e.g., a Record's Names.
+func NewItemEncoder(w io.Writer, names NameMap) *ItemEncoder {
+	e := &ItemEncoder{w: bufio.NewWriter(w)}
+	types := make([]ItemType, 0, len(names))
+	for t := range names {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	b := append([]byte(wireMagic), wireVersion)
+	b = binary.AppendUvarint(b, uint64(len(types)))
+	for _, t := range types {
+		b = binary.AppendVarint(b, int64(t))
+		b = appendString(b, names[t])
+	}
+	_, e.err = e.w.Write(b)
+	return e
+}
+
+// Encode writes item.  Once a write fails all further items are
+// discarded and the error is returned, as well as by Flush.
+func (e *ItemEncoder) Encode(item Item) error {
+	if e.err != nil {
+		return e.err
+	}
+	b := binary.AppendVarint(e.buf[:0], int64(item.Type))
+	b = binary.AppendVarint(b, item.Pos-e.pos)
+	b = appendString(b, item.Value)
+	if item.Type == ItemError {
+		b = binary.AppendVarint(b, int64(item.Err.State))
+		b = appendString(b, item.Err.Binding)
+	}
+	e.buf, e.pos = b, item.Pos
+	_, e.err = e.w.Write(b)
+	return e.err
+}
+
+// Flush writes any buffered items to the underlying writer, returning
+// the first error encountered.
+func (e *ItemEncoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// EncodeItems writes the items of l to w, through to its ItemEOF, in
+// the format read by an ItemDecoder, with a header naming the item
+// types in names.
+func EncodeItems(l *Lexer, w io.Writer, names NameMap) error {
+	e := NewItemEncoder(w, names)
+	for {
+		item, ok := l.nextItem()
+		if !ok {
+			break
+		}
+		if err := e.Encode(item); err != nil {
+			l.Close()
+			return err
+		}
+		if item.Type == ItemEOF {
+			break
+		}
+	}
+	return e.Flush()
+}
+
+// appendString appends s to b as a uvarint length and its bytes.
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// ItemDecoder reads Items written by an ItemEncoder.
+type ItemDecoder struct {
+	r     *bufio.Reader
+	names NameMap
+	pos   int64
+}
+
+// errWireFormat is returned by an ItemDecoder for malformed input.
+var errWireFormat = errors.New("lexrec: malformed item stream")
+
+// NewItemDecoder returns an ItemDecoder reading from r, having read
+// the header of the stream.  It returns an error if the stream is not
+// an Item stream, or is of an unsupported version.
+func NewItemDecoder(r io.Reader) (*ItemDecoder, error) {
+	d := &ItemDecoder{r: bufio.NewReader(r), names: NameMap{}}
+	magic := make([]byte, len(wireMagic)+1)
+	if _, err := io.ReadFull(d.r, magic); err != nil {
+		return nil, errWireFormat
+	}
+	if string(magic[:len(wireMagic)]) != wireMagic {
+		return nil, errWireFormat
+	}
+	if v := magic[len(wireMagic)]; v != wireVersion {
+		return nil, fmt.Errorf("lexrec: unsupported item stream version %d", v)
+	}
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return nil, errWireFormat
+	}
+	for ; n > 0; n-- {
+		t, err := binary.ReadVarint(d.r)
+		if err != nil {
+			return nil, errWireFormat
+		}
+		name, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		d.names[ItemType(t)] = name
+	}
+	return d, nil
+}
+
+// Names returns the names of the item types given in the header of
+// the stream.
+func (d *ItemDecoder) Names() NameMap {
+	return d.names
+}
+
+// Decode returns the next item of the stream, or io.EOF at its end.
+func (d *ItemDecoder) Decode() (item Item, err error) {
+	t, err := binary.ReadVarint(d.r)
+	if err == io.EOF {
+		return item, io.EOF
+	}
+	if err != nil {
+		return item, errWireFormat
+	}
+	delta, err := binary.ReadVarint(d.r)
+	if err != nil {
+		return item, errWireFormat
+	}
+	item.Type = ItemType(t)
+	item.Pos = d.pos + delta
+	if item.Value, err = d.string(); err != nil {
+		return item, err
+	}
+	if item.Type == ItemError {
+		state, err := binary.ReadVarint(d.r)
+		if err != nil {
+			return item, errWireFormat
+		}
+		item.Err.State = int(state)
+		if item.Err.Binding, err = d.string(); err != nil {
+			return item, err
+		}
+	}
+	d.pos = item.Pos
+	return item, nil
+}
+
+// string reads a string written by appendString.
+func (d *ItemDecoder) string() (string, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil || n > math.MaxInt64 {
+		return "", errWireFormat
+	}
+	// copied rather than read into a buffer of n bytes, so that a
+	// corrupt length cannot exhaust memory.
+	var sb strings.Builder
+	if _, err := io.CopyN(&sb, d.r, int64(n)); err != nil {
+		return "", errWireFormat
+	}
+	return sb.String(), nil
+}
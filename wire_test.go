@@ -0,0 +1,77 @@
+package lexrec
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestItemEncoder(t *testing.T) {
+	rec := Record{
+		Buflen:  16,
+		ErrorFn: SkipPast("\n"),
+		Names:   NameMap{ItemA: "name", ItemB: "count"},
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	input := "ab 1\ncd x\nef 22\n"
+	expect := lexAll(t, "TestItemEncoder", input, rec)
+
+	l, err := NewLexer("TestItemEncoder", strings.NewReader(input), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := EncodeItems(l, buf, rec.Names); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewItemDecoder(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d.Names(), rec.Names) {
+		t.Errorf("expected names %v, got %v", rec.Names, d.Names())
+	}
+	var items []Item
+	for {
+		item, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+	if len(items) != len(expect) {
+		t.Fatalf("expected %v, got %v", expect, items)
+	}
+	for i := range expect {
+		expect[i].Err.Cause = nil
+		if !sameItem(items[i], expect[i]) {
+			t.Errorf("expected %v, got %v", expect[i], items[i])
+		}
+	}
+}
+
+func TestItemDecoderErrors(t *testing.T) {
+	if _, err := NewItemDecoder(strings.NewReader("LXRX\x01\x00")); err == nil {
+		t.Errorf("expected an error for a bad magic number")
+	}
+	if _, err := NewItemDecoder(strings.NewReader("LXRS\x02\x00")); err == nil {
+		t.Errorf("expected an error for an unsupported version")
+	}
+	d, err := NewItemDecoder(strings.NewReader("LXRS\x01\x00\x0c\x00\xff\xff\xff\xff\x0f"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Decode(); err == nil || err == io.EOF {
+		t.Errorf("expected an error for a truncated item, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package lexrec
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Clone returns a copy of i whose Value and Bytes no longer share
+// memory with the Lexer's read buffer or an Arena, so that they remain
+// valid after the record they came from has been passed.  Items lexed
+// from a Record with ZeroCopy set, or with an Arena, must be cloned to
+// be kept, e.g.:
+//
+//	for l.Scan() {
+//		if item := l.Item(); item.Type == ItemRemoteHost {
+//			hosts = append(hosts, item.Clone())
+//		}
+//	}
+func (i Item) Clone() Item {
+	i.Value = strings.Clone(i.Value)
+	if i.Bytes != nil {
+		i.Bytes = bytes.Clone(i.Bytes)
+	}
+	return i
+}
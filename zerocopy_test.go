@@ -0,0 +1,67 @@
+package lexrec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestZeroCopy(t *testing.T) {
+	rec := Record{
+		Buflen:   4,
+		ZeroCopy: true,
+		ErrorFn:  SkipPast("\n"),
+		States: []Binding{
+			{ItemA, Letters, true},
+			{ItemIgnore, Accept(" ", true), false},
+			{ItemB, Digits, true},
+			{ItemIgnore, Accept("\n", true), false}},
+	}
+	if _, err := NewLexer("TestZeroCopy", strings.NewReader(""), rec); err == nil {
+		t.Errorf("expected NewLexer to reject a ZeroCopy Record")
+	}
+	if _, err := NewStepper("TestZeroCopy", strings.NewReader(""), rec); err == nil {
+		t.Errorf("expected NewStepper to reject a ZeroCopy Record")
+	}
+
+	input := "abc 1\ndefgh 22\nij x\nklm 333\n"
+	l, err := NewLexerSync("TestZeroCopy", strings.NewReader(input), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"abc 1", "defgh 22", "ij", "klm 333"}
+	var kept []Item
+	for _, e := range expect {
+		items, _ := l.NextRecord()
+		values := []string{}
+		for _, item := range items {
+			if item.Type == ItemError {
+				continue
+			}
+			if string(item.Bytes) != item.Value {
+				t.Errorf("expected Bytes %q, got %q", item.Value, item.Bytes)
+			}
+			values = append(values, item.Value)
+		}
+		if got := strings.Join(values, " "); got != e {
+			t.Errorf("expected %q, got %q", e, got)
+		}
+		kept = append(kept, items[0].Clone())
+	}
+	for i, e := range []string{"abc", "defgh", "ij", "klm"} {
+		if kept[i].Value != e || string(kept[i].Bytes) != e {
+			t.Errorf("expected clone %q, got %q %q", e, kept[i].Value, kept[i].Bytes)
+		}
+	}
+
+	// Value is a copy, unchanged once the buffer is reused.
+	l, err = NewLexerSync("TestZeroCopy", strings.NewReader(input), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := l.NextRecord()
+	for l.Scan() {
+	}
+	if first[0].Value != "abc" {
+		t.Errorf("expected %q, got %q", "abc", first[0].Value)
+	}
+}